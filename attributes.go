@@ -0,0 +1,231 @@
+package birdland
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// AttributeStore holds the attributes of the items of the catalogue, such as
+// their genre, label or the regions where they are available. An attribute
+// may take several values for the same item. The store can be updated while
+// the engine serves recommendations.
+type AttributeStore struct {
+	mu         sync.RWMutex
+	attributes map[int]map[string][]string
+}
+
+// NewAttributeStore returns an empty attribute store.
+func NewAttributeStore() *AttributeStore {
+	return &AttributeStore{attributes: make(map[int]map[string][]string)}
+}
+
+// Set replaces the values of the attribute of item. Setting no value removes
+// the attribute.
+func (s *AttributeStore) Set(item int, attribute string, values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(values) == 0 {
+		delete(s.attributes[item], attribute)
+		return
+	}
+	if _, ok := s.attributes[item]; !ok {
+		s.attributes[item] = make(map[string][]string)
+	}
+	s.attributes[item][attribute] = append([]string(nil), values...)
+}
+
+// Get returns the values of the attribute of item.
+func (s *AttributeStore) Get(item int, attribute string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.attributes[item][attribute]
+}
+
+// Has reports whether the attribute of item takes the given value.
+func (s *AttributeStore) Has(item int, attribute, value string) bool {
+	for _, v := range s.Get(item, attribute) {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RuleKind is the type of a business rule.
+type RuleKind int
+
+const (
+	Exclude RuleKind = iota // drop the items whose attribute takes Value
+	Require                 // keep only the items whose attribute takes Value
+	Quota                   // keep at most Max items per value of the attribute
+)
+
+// Rule is a business rule applied to the recommended items, between the
+// aggregation of the walks and the final ranking. Rules are usually written
+// in the short form read by ParseRule.
+type Rule struct {
+	Kind      RuleKind `yaml:"kind"`
+	Attribute string   `yaml:"attribute"`
+	Value     string   `yaml:"value"` // for Exclude and Require rules
+	Max       int      `yaml:"max"`   // for Quota rules
+}
+
+// ParseRule reads a rule written in one of the following forms:
+//
+//	exclude explicit=true   no explicit items
+//	require region=FR       only items available in FR
+//	max 3 per label         at most 3 items per label
+//
+// Invalid rules return an error that matches ErrInvalidConfig.
+func ParseRule(rule string) (Rule, error) {
+	fields := strings.Fields(rule)
+	if len(fields) == 0 {
+		return Rule{}, errors.Wrap(ErrInvalidConfig, "empty rule")
+	}
+
+	switch fields[0] {
+	case "exclude", "require":
+		if len(fields) != 2 {
+			return Rule{}, errors.Wrapf(ErrInvalidConfig, "rule %q must be of the form '%s attribute=value'", rule, fields[0])
+		}
+		kv := strings.SplitN(fields[1], "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return Rule{}, errors.Wrapf(ErrInvalidConfig, "rule %q must be of the form '%s attribute=value'", rule, fields[0])
+		}
+		kind := Exclude
+		if fields[0] == "require" {
+			kind = Require
+		}
+		return Rule{Kind: kind, Attribute: kv[0], Value: kv[1]}, nil
+	case "max":
+		if len(fields) != 4 || fields[2] != "per" {
+			return Rule{}, errors.Wrapf(ErrInvalidConfig, "rule %q must be of the form 'max n per attribute'", rule)
+		}
+		max, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Rule{}, errors.Wrapf(ErrInvalidConfig, "rule %q: invalid maximum %q", rule, fields[1])
+		}
+		r := Rule{Kind: Quota, Attribute: fields[3], Max: max}
+		return r, validateRule(r)
+	default:
+		return Rule{}, errors.Wrapf(ErrInvalidConfig, "rule %q: unknown rule %q", rule, fields[0])
+	}
+}
+
+// ParseRules reads each of the rules with ParseRule.
+func ParseRules(rules ...string) ([]Rule, error) {
+	parsed := make([]Rule, len(rules))
+	for i, rule := range rules {
+		r, err := ParseRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = r
+	}
+
+	return parsed, nil
+}
+
+// ApplyRules keeps at most k of the recommended items that comply with the
+// engine's rules, in order, so that rules also apply to the output of
+// Process followed by RecommendItems or RerankMMR for instance. A negative k
+// keeps no items.
+func (b *Bird) ApplyRules(recommended []int, k int) []int {
+	if k < 0 {
+		k = 0
+	}
+
+	return b.rankItems(recommended, nil, k)
+}
+
+// rankItems keeps at most k of the recommended items that are not excluded
+// and comply with the engine's rules.
+func (b *Bird) rankItems(recommended []int, excluded map[int]bool, k int) []int {
+	return applyRules(b.Attributes, b.Rules, recommended, excluded, k)
+}
+
+// applyRules keeps at most k of the recommended items that are not excluded
+// and comply with the rules, in order. Quotas are filled by the first items
+// of the list.
+func applyRules(store *AttributeStore, rules []Rule, recommended []int, excluded map[int]bool, k int) []int {
+	if store == nil || len(rules) == 0 {
+		return filterRecommendations(recommended, excluded, k)
+	}
+
+	counts := make([]map[string]int, len(rules))
+	for i, rule := range rules {
+		if rule.Kind == Quota {
+			counts[i] = make(map[string]int)
+		}
+	}
+
+	filtered := make([]int, 0, k)
+	for _, item := range recommended {
+		if len(filtered) == k {
+			break
+		}
+		if excluded[item] || !complies(store, rules, counts, item) {
+			continue
+		}
+		filtered = append(filtered, item)
+		for i, rule := range rules {
+			if rule.Kind != Quota {
+				continue
+			}
+			for _, v := range store.Get(item, rule.Attribute) {
+				counts[i][v]++
+			}
+		}
+	}
+
+	return filtered
+}
+
+// complies reports whether item can be added to the recommendations given
+// the number of items already counted against each quota.
+func complies(store *AttributeStore, rules []Rule, counts []map[string]int, item int) bool {
+	for i, rule := range rules {
+		switch rule.Kind {
+		case Exclude:
+			if store.Has(item, rule.Attribute, rule.Value) {
+				return false
+			}
+		case Require:
+			if !store.Has(item, rule.Attribute, rule.Value) {
+				return false
+			}
+		case Quota:
+			for _, v := range store.Get(item, rule.Attribute) {
+				if counts[i][v] >= rule.Max {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// validateRule checks that the rule can be applied.
+func validateRule(rule Rule) error {
+	if rule.Attribute == "" {
+		return errors.Wrap(ErrInvalidConfig, "rules must refer to an attribute")
+	}
+	switch rule.Kind {
+	case Exclude, Require:
+		return nil
+	case Quota:
+		if rule.Max < 1 {
+			return errors.Wrap(ErrInvalidConfig, "quotas must be greater than or equal to 1")
+		}
+		return nil
+	default:
+		return errors.Wrapf(ErrInvalidConfig, "unknown rule kind %d", rule.Kind)
+	}
+}
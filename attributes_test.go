@@ -0,0 +1,138 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type ParseRuleCase struct {
+	Rule     string
+	Expected Rule
+	Valid    bool
+}
+
+var parseRuleTable = []ParseRuleCase{
+	{"exclude explicit=true", Rule{Kind: Exclude, Attribute: "explicit", Value: "true"}, true},
+	{"require region=FR", Rule{Kind: Require, Attribute: "region", Value: "FR"}, true},
+	{"max 3 per label", Rule{Kind: Quota, Attribute: "label", Max: 3}, true},
+	{"  max 1   per genre ", Rule{Kind: Quota, Attribute: "genre", Max: 1}, true},
+	{"", Rule{}, false},
+	{"exclude explicit", Rule{}, false},
+	{"require =FR", Rule{}, false},
+	{"max three per label", Rule{}, false},
+	{"max 0 per label", Rule{}, false},
+	{"max 3 label", Rule{}, false},
+	{"shuffle", Rule{}, false},
+}
+
+func TestParseRule(t *testing.T) {
+	for _, ex := range parseRuleTable {
+		rule, err := ParseRule(ex.Rule)
+		if !ex.Valid {
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("ParseRule: %q: expected an invalid configuration error, got %v", ex.Rule, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRule: %q: raised an error: %v", ex.Rule, err)
+			continue
+		}
+		if rule != ex.Expected {
+			t.Errorf("ParseRule: %q: expected %+v, got %+v", ex.Rule, ex.Expected, rule)
+		}
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	store := NewAttributeStore()
+	store.Set(0, "label", "A")
+	store.Set(1, "label", "A")
+	store.Set(1, "explicit", "true")
+	store.Set(2, "label", "A")
+	store.Set(3, "label", "B")
+	store.Set(3, "region", "US")
+	store.Set(4, "region", "FR", "US")
+	store.Set(5, "label", "B")
+	store.Set(5, "region", "FR")
+
+	recommended := []int{0, 1, 2, 3, 4, 5}
+	cases := []struct {
+		Name     string
+		Rules    []string
+		Excluded map[int]bool
+		K        int
+		Expected []int
+	}{
+		{"No rules", nil, nil, 10, []int{0, 1, 2, 3, 4, 5}},
+		{"Excluded items", nil, map[int]bool{0: true}, 2, []int{1, 2}},
+		{"No explicit items", []string{"exclude explicit=true"}, nil, 10, []int{0, 2, 3, 4, 5}},
+		{"Only items available in FR", []string{"require region=FR"}, nil, 10, []int{4, 5}},
+		{"At most 1 per label", []string{"max 1 per label"}, nil, 10, []int{0, 3, 4}},
+		{"Combined rules", []string{"exclude explicit=true", "max 2 per label"}, map[int]bool{0: true}, 3, []int{2, 3, 4}},
+	}
+	for _, ex := range cases {
+		rules, err := ParseRules(ex.Rules...)
+		if err != nil {
+			t.Fatalf("ApplyRules: %s: parsing the rules raised an error: %v", ex.Name, err)
+		}
+		filtered := applyRules(store, rules, recommended, ex.Excluded, ex.K)
+		if len(filtered) != len(ex.Expected) {
+			t.Errorf("ApplyRules: %s: expected %v, got %v", ex.Name, ex.Expected, filtered)
+			continue
+		}
+		for i := range filtered {
+			if filtered[i] != ex.Expected[i] {
+				t.Errorf("ApplyRules: %s: expected %v, got %v", ex.Name, ex.Expected, filtered)
+				break
+			}
+		}
+	}
+}
+
+func TestEngineRules(t *testing.T) {
+	store := NewAttributeStore()
+	store.Set(2, "explicit", "true")
+	rules, err := ParseRules("exclude explicit=true")
+	if err != nil {
+		t.Fatalf("EngineRules: parsing the rules raised an error: %v", err)
+	}
+
+	cfg := NewBirdCfg()
+	cfg.Draws = 100
+	usersToItems := [][]int{{0, 1}, {0, 1, 2}, {1, 3}}
+	engine, err := New(WithCfg(cfg), WithInteractions(usersToItems), WithAttributes(store, rules...))
+	if err != nil {
+		t.Fatalf("EngineRules: initialization raised an error: %v", err)
+	}
+
+	recommended, err := engine.(*Bird).RecommendForUser(0, 10)
+	if err != nil {
+		t.Fatalf("EngineRules: raised an error: %v", err)
+	}
+	if len(recommended) != 1 || recommended[0] != 3 {
+		t.Errorf("EngineRules: expected item 3, got %v", recommended)
+	}
+
+	items, referrers, err := engine.(*Bird).Process([]QueryItem{{Item: 0, Weight: 1}})
+	if err != nil {
+		t.Fatalf("EngineRules: processing raised an error: %v", err)
+	}
+	recommended = engine.(*Bird).ApplyRules(RecommendItems(items, referrers), 10)
+	if len(recommended) == 0 || contains(recommended, 2) {
+		t.Errorf("EngineRules: expected the explicit item 2 to be excluded, got %v", recommended)
+	}
+	if recommended = engine.(*Bird).ApplyRules(RecommendItems(items, referrers), -1); len(recommended) != 0 {
+		t.Errorf("EngineRules: expected no items for a negative k, got %v", recommended)
+	}
+
+	_, err = New(WithInteractions(usersToItems), WithAttributes(nil, rules...))
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("EngineRules: rules without attributes should raise an invalid configuration error, got %v", err)
+	}
+	_, err = New(WithInteractions(usersToItems), WithAttributes(store, Rule{Kind: Quota, Attribute: "label"}))
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("EngineRules: a null quota should raise an invalid configuration error, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package birdland
+
+import "github.com/pkg/errors"
+
+// RecommendAudience returns the k users most likely to engage with item among
+// those who have not interacted with it yet, for instance to target the
+// release of a new song at the listeners of its first fans. The walks start
+// from the histories of the users who interacted with item, as built by
+// UserQuery, and the users they visit are ranked with RecommendUsers.
+func (b *Bird) RecommendAudience(item, k int) ([]int, error) {
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+	query, err := b.audienceQuery(item)
+	if err != nil {
+		return nil, err
+	}
+
+	walks, _, err := b.ProcessWalks(query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot walk from the neighbourhood of item %d", item)
+	}
+	items, referrers := walks.Flatten()
+
+	fans := make(map[int]bool, len(b.ItemsToUsers[item]))
+	for _, user := range b.ItemsToUsers[item] {
+		fans[user] = true
+	}
+
+	return filterRecommendations(RecommendUsers(items, referrers), fans, k), nil
+}
+
+// audienceQuery merges the histories of the users who interacted with item
+// into a single query. The item itself is left out since walks starting from
+// it could only reach the users who already interacted with it.
+func (b *Bird) audienceQuery(item int) ([]QueryItem, error) {
+	if item < 0 || item >= len(b.ItemWeights) {
+		return nil, &UnknownItemError{Item: item}
+	}
+
+	weights := make(map[int]float64)
+	for _, user := range b.ItemsToUsers[item] {
+		history, err := b.UserQuery(user)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range history {
+			if q.Item != item {
+				weights[q.Item] += q.Weight
+			}
+		}
+	}
+
+	if len(weights) == 0 {
+		return nil, errors.Wrapf(ErrEmptyQuery, "the users of item %d did not interact with any other item", item)
+	}
+
+	return sortedQuery(weights), nil
+}
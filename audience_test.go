@@ -0,0 +1,48 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRecommendAudience(t *testing.T) {
+	// Users 0 and 1 have item 0. Users 2 and 3 share item 1 with them, but
+	// user 4 only has items that are not related to item 0.
+	usersToItems := [][]int{{0, 1}, {0, 1}, {1, 2}, {1}, {3}}
+	cfg := NewBirdCfg()
+	cfg.Draws = 100
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1, 1}, usersToItems)
+	if err != nil {
+		t.Fatalf("RecommendAudience: Bird initialization raised an error: %v", err)
+	}
+
+	audience, err := bird.RecommendAudience(0, 10)
+	if err != nil {
+		t.Fatalf("RecommendAudience: raised an error: %v", err)
+	}
+	if len(audience) != 2 || !contains(audience, 2) || !contains(audience, 3) {
+		t.Errorf("RecommendAudience: expected users 2 and 3, got %v", audience)
+	}
+
+	audience, err = bird.RecommendAudience(0, 1)
+	if err != nil {
+		t.Fatalf("RecommendAudience: raised an error: %v", err)
+	}
+	if len(audience) != 1 {
+		t.Errorf("RecommendAudience: expected 1 user, got %v", audience)
+	}
+
+	if _, err := bird.RecommendAudience(5, 10); !errors.Is(err, ErrUnknownItem) {
+		t.Errorf("RecommendAudience: expected an unknown item error, got %v", err)
+	}
+	if _, err := bird.RecommendAudience(4, 10); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("RecommendAudience: expected an empty query error for an item no one has interacted with, got %v", err)
+	}
+	if _, err := bird.RecommendAudience(3, 10); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("RecommendAudience: expected an empty query error for an isolated item, got %v", err)
+	}
+	if _, err := bird.RecommendAudience(0, -1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("RecommendAudience: expected an invalid configuration error for a negative k, got %v", err)
+	}
+}
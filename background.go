@@ -0,0 +1,50 @@
+package birdland
+
+import "github.com/pkg/errors"
+
+// EstimateBackground estimates the probability that random walks visit each
+// item whatever the query, to be used with RecommendLift. The walks start
+// from numQueries single-item queries drawn uniformly among the items someone
+// has interacted with. Every item is given one extra visit so that none has a
+// zero probability. It is typically computed in a batch job, as the
+// distribution only changes with the graph.
+func (b *Bird) EstimateBackground(numQueries int) ([]float64, error) {
+	if numQueries < 1 {
+		return nil, errors.Wrap(ErrInvalidConfig, "the number of queries must be greater than or equal to 1")
+	}
+
+	var candidates []int
+	for item, users := range b.ItemsToUsers {
+		if len(users) > 0 && b.ItemWeights[item] > 0 {
+			candidates = append(candidates, item)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, errors.Wrap(ErrEmptyQuery, "no one has interacted with the items")
+	}
+
+	visits := make([]float64, len(b.ItemWeights))
+	for i := range visits {
+		visits[i] = 1
+	}
+	totalVisits := float64(len(visits))
+	for q := 0; q < numQueries; q++ {
+		item := candidates[b.RandSource.Intn(len(candidates))]
+		walks, _, err := b.ProcessWalks([]QueryItem{{Item: item, Weight: 1}})
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot walk from item %d", item)
+		}
+		for _, w := range walks {
+			for _, step := range w.Steps {
+				visits[step.Item]++
+				totalVisits++
+			}
+		}
+	}
+
+	for i := range visits {
+		visits[i] /= totalVisits
+	}
+
+	return visits, nil
+}
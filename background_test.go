@@ -0,0 +1,46 @@
+package birdland
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestEstimateBackground(t *testing.T) {
+	// item 0 is shared by every user, item 3 has no users
+	cfg := NewBirdCfg()
+	cfg.Draws = 100
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1}, [][]int{{0, 1}, {0, 2}, {0}})
+	if err != nil {
+		t.Fatalf("EstimateBackground: Bird initialization raised an error: %v", err)
+	}
+
+	background, err := bird.EstimateBackground(20)
+	if err != nil {
+		t.Fatalf("EstimateBackground: raised an error: %v", err)
+	}
+	if len(background) != 4 {
+		t.Fatalf("EstimateBackground: expected a probability for each of the 4 items, got %v", background)
+	}
+
+	var total float64
+	for item, p := range background {
+		if p <= 0 {
+			t.Errorf("EstimateBackground: item %d has a null probability", item)
+		}
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("EstimateBackground: the probabilities sum to %v", total)
+	}
+	for item := 1; item < 4; item++ {
+		if background[item] >= background[0] {
+			t.Errorf("EstimateBackground: the hub should be the most visited item, got %v", background)
+		}
+	}
+
+	if _, err := bird.EstimateBackground(0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("EstimateBackground: expected an invalid configuration error, got %v", err)
+	}
+}
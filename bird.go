@@ -1,7 +1,6 @@
 package birdland
 
 import (
-	"fmt"
 	"math/rand"
 	"time"
 
@@ -15,14 +14,24 @@ type QueryItem struct {
 }
 
 type BirdCfg struct {
-	Depth int `yaml:"depth"`
-	Draws int `yaml:"draws"`
+	Depth        int           `yaml:"depth"`
+	Draws        int           `yaml:"draws"`
+	HistoryLimit int           `yaml:"history_limit"` // number of items of a user's history used as a query, 0 uses them all
+	HistoryOrder HistoryOrder  `yaml:"history_order"` // which items of the history are kept when it is limited
+	HalfLife     time.Duration `yaml:"half_life"`     // time for the weight of timed interactions to halve, 0 disables the decay
+	Window       time.Duration `yaml:"window"`        // maximum time between two interactions of a temporal walk, 0 for no limit
+	Fallbacks    []Source      `yaml:"fallbacks"`     // tried in order by Recommend when the walks cannot be performed
 }
 
 func NewBirdCfg() *BirdCfg {
 	cfg := BirdCfg{
-		Depth: 1,
-		Draws: 1000,
+		Depth:        1,
+		Draws:        1000,
+		HistoryLimit: 0,
+		HistoryOrder: MostRecent,
+		HalfLife:     0,
+		Window:       0,
+		Fallbacks:    nil,
 	}
 
 	return &cfg
@@ -31,34 +40,35 @@ func NewBirdCfg() *BirdCfg {
 // Bird is a recommendation engine that performs random walks on the
 // user-item bipartite graph.
 type Bird struct {
-	Cfg               *BirdCfg
-	ItemWeights       []float64              // global weight attributed to items
-	UsersToItems      [][]int                // user-item adjacency matrix
-	ItemsToUsers      [][]int                // item-user adjacency matrix
-	UserItemsSamplers []sampler.AliasSampler // samplers to randomly draw items from a user's collection
-	RandSource        *rand.Rand
+	Cfg                *BirdCfg
+	ItemWeights        []float64              // global weight attributed to items
+	UsersToItems       [][]int                // user-item adjacency matrix
+	ItemsToUsers       [][]int                // item-user adjacency matrix
+	InteractionWeights [][]float64            // weight of each interaction in UsersToItems, nil for unweighted graphs
+	UserItemsSamplers  []sampler.AliasSampler // samplers to randomly draw items from a user's collection
+	Attributes         *AttributeStore        // attributes of the items, nil if the engine has no rules
+	Rules              []Rule                 // business rules applied to the recommended items
+	WeightsUpdated     time.Time              // time at which the interaction weights were last decayed
+	RandSource         *rand.Rand
+
+	timeline *timeline // chronological interactions, for temporal walks
 }
 
 // NewBird creates a new recommender from input data.
 func NewBird(cfg *BirdCfg, itemWeights []float64, usersToItems [][]int) (*Bird, error) {
-	if cfg.Depth < 1 {
-		return nil, errors.New("the depth must be greater than or equal to 1")
-	}
-
-	if cfg.Draws < 1 {
-		return nil, errors.New("the number of draws must be greater than or equal to 1")
-	}
-
-	randSource := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	err := validateBirdInputs(itemWeights, usersToItems)
+	engine, err := New(WithCfg(cfg), WithItemWeights(itemWeights), WithInteractions(usersToItems))
 	if err != nil {
-		return &Bird{}, errors.Wrap(err, "invalid input")
+		return nil, err
 	}
 
+	return engine.(*Bird), nil
+}
+
+// newBird creates a new recommender from validated input data.
+func newBird(cfg *BirdCfg, randSource *rand.Rand, itemWeights []float64, usersToItems [][]int) (*Bird, error) {
 	userItemsSampler, err := initUserItemsSamplers(randSource, itemWeights, usersToItems)
 	if err != nil {
-		return &Bird{}, errors.Wrap(err, "cannot initialize samplers")
+		return nil, errors.Wrap(err, "cannot initialize samplers")
 	}
 
 	// we sacrifice memory for speed by storing the two complementary adjacency lists.
@@ -80,60 +90,89 @@ func NewBird(cfg *BirdCfg, itemWeights []float64, usersToItems [][]int) (*Bird,
 // starting from them. Returns a list of items and a list of
 // users who referred this item in the walk.
 func (b *Bird) Process(query []QueryItem) ([]int, []int, error) {
+	walks, _, err := b.ProcessWalks(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, referrers := walks.Flatten()
+
+	return items, referrers, nil
+}
+
+// ProcessWalks randomly samples items from the query and performs random
+// walks starting from them. Unlike Process, it returns the full path of each
+// walk so visits can be attributed to a query item and to a depth, along with
+// the query items that were dropped because no one has interacted with them.
+func (b *Bird) ProcessWalks(query []QueryItem) (Walks, []QueryItem, error) {
 	if len(query) == 0 {
-		return nil, nil, errors.New("empty query")
+		return nil, nil, ErrEmptyQuery
 	}
 
-	stepItems, err := b.sampleItemsFromQuery(query)
+	stepItems, dropped, err := b.sampleItemsFromQuery(query)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "cannot sample items")
 	}
 
-	var items []int
-	var referrers []int
+	walks := newWalks(stepItems, b.Cfg.Depth)
+	if b.timeline != nil {
+		b.temporalWalks(walks)
+		return walks, dropped, nil
+	}
+
 	for d := 0; d < b.Cfg.Depth; d++ {
-		stepItems, stepReferrers, err := b.step(stepItems)
+		var stepReferrers []int
+		stepItems, stepReferrers, err = b.step(stepItems)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "cannot step through items")
 		}
-		items = append(items, stepItems...)
-		referrers = append(referrers, stepReferrers...)
+		for i := range walks {
+			walks[i].Steps[d] = Step{Referrer: stepReferrers[i], Item: stepItems[i]}
+		}
 	}
 
-	return items, referrers, nil
+	return walks, dropped, nil
 }
 
 // sampleItemsFromQuery returns a slice of items that will be the starting
 // points of the subsequent random walks. If the query refers to an item that
 // has no record in ItemsToUsers (i.e. no one has interacted with it), the item
-// is ignored.
-func (b *Bird) sampleItemsFromQuery(query []QueryItem) ([]int, error) {
+// is given a zero weight and returned in the list of dropped query items.
+// Items that are not part of the graph return an UnknownItemError.
+func (b *Bird) sampleItemsFromQuery(query []QueryItem) ([]int, []QueryItem, error) {
 
+	var dropped []QueryItem
+	var totalWeight float64
 	weights := make([]float64, len(query))
 	items := make([]int, len(query))
 	for i, q := range query {
-		weights[i] = q.Weight * b.ItemWeights[q.Item]
+		if q.Item < 0 || q.Item >= len(b.ItemWeights) {
+			return nil, nil, &UnknownItemError{Item: q.Item}
+		}
 		items[i] = q.Item
+		if len(b.ItemsToUsers[q.Item]) == 0 {
+			dropped = append(dropped, q)
+			continue
+		}
+		weights[i] = q.Weight * b.ItemWeights[q.Item]
+		totalWeight += weights[i]
+	}
+
+	if totalWeight == 0 {
+		return nil, nil, errors.Wrap(ErrEmptyQuery, "no one has interacted with the items in the query")
 	}
+
 	s, err := sampler.NewAliasSampler(b.RandSource, weights)
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot create sampler")
+		return nil, nil, errors.Wrap(err, "cannot create sampler")
 	}
 
 	sampledItems := make([]int, b.Cfg.Draws)
 	for i, iid := range s.Sample(b.Cfg.Draws) {
-		if len(b.ItemsToUsers[items[iid]]) == 0 {
-			continue
-		}
 		sampledItems[i] = items[iid]
 	}
 
-	if len(sampledItems) == 0 {
-		return nil, errors.New("no items were sampled," +
-			"check that the query refers to actual items.")
-	}
-
-	return sampledItems, nil
+	return sampledItems, dropped, nil
 }
 
 // step performs one random walk step for each incoming item. It returns a
@@ -145,7 +184,7 @@ func (b *Bird) step(items []int) ([]int, []int, error) {
 	for i, item := range items {
 		relatedUsers := b.ItemsToUsers[item]
 		if len(relatedUsers) == 0 {
-			return nil, nil, fmt.Errorf("cannot perform step: no one has interacted with item %d", item)
+			return nil, nil, &DeadEndError{Item: item}
 		}
 		referrers[i] = relatedUsers[b.RandSource.Intn(len(relatedUsers))]
 	}
@@ -192,16 +231,52 @@ func initUserItemsSamplers(randSource *rand.Rand,
 	return userItemsSamplers, nil
 }
 
+// validateBirdCfg checks that the depth and number of draws allow the
+// engine to perform random walks, and that the history settings are valid.
+func validateBirdCfg(cfg *BirdCfg) error {
+	if cfg.Depth < 1 {
+		return errors.Wrap(ErrInvalidConfig, "the depth must be greater than or equal to 1")
+	}
+
+	if cfg.Draws < 1 {
+		return errors.Wrap(ErrInvalidConfig, "the number of draws must be greater than or equal to 1")
+	}
+
+	if cfg.HistoryLimit < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the history limit must be positive")
+	}
+
+	if cfg.HalfLife < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the half-life must be positive")
+	}
+
+	if cfg.Window < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the window of temporal walks must be positive")
+	}
+
+	for _, fallback := range cfg.Fallbacks {
+		if fallback <= FromWalks || fallback > FromFriends {
+			return errors.Wrapf(ErrInvalidConfig, "unknown fallback %d", fallback)
+		}
+	}
+
+	if cfg.HistoryOrder != MostRecent && cfg.HistoryOrder != HighestWeight {
+		return errors.Wrapf(ErrInvalidConfig, "unknown history order %d", cfg.HistoryOrder)
+	}
+
+	return nil
+}
+
 // validateBirdInput checks the validity of the data fed to Bird.  It returns
 // an error when it identifies a discrepancy that could make the processing
 // algorithm crash.
 func validateBirdInputs(itemWeights []float64, usersToItems [][]int) error {
 
 	if len(itemWeights) == 0 {
-		return errors.New("empty slice of item weights")
+		return errors.Wrap(ErrInvalidInput, "empty slice of item weights")
 	}
 	if len(usersToItems) == 0 {
-		return errors.New("empty users to items adjacency table")
+		return errors.Wrap(ErrInvalidInput, "empty users to items adjacency table")
 	}
 
 	// Check that there is a weight for each item present in adjacency tables.
@@ -215,14 +290,15 @@ func validateBirdInputs(itemWeights []float64, usersToItems [][]int) error {
 		}
 	}
 	if numItems <= m {
-		return errors.New("UsersToItems references more items itemWeights")
+		return errors.Wrap(ErrInvalidInput, "UsersToItems references more items than itemWeights")
 	}
 
 	return nil
 }
 
 // permuteAdjacencyList transforms the UsersToItems adjacency list into the
-// complementary ItemsToUsers adjacency list.
+// complementary ItemsToUsers adjacency list. The users related to each item
+// are sorted by ascending id, which Weaver relies on to find connections.
 func permuteAdjacencyList(numItems int, usersToItems [][]int) [][]int {
 
 	itemsToUsers := make([][]int, numItems)
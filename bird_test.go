@@ -3,6 +3,8 @@ package birdland
 import (
 	"math/rand"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 type BirdInitCase struct {
@@ -99,6 +101,135 @@ func TestBirdInitialization(t *testing.T) {
 	}
 }
 
+func TestBirdProcessWalks(t *testing.T) {
+	usersToItems := [][]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}}
+	cfg := NewBirdCfg()
+	cfg.Depth = 3
+	cfg.Draws = 100
+
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1}, usersToItems)
+	if err != nil {
+		t.Fatalf("ProcessWalks: Bird initialization raised an error: %v", err)
+	}
+
+	walks, _, err := bird.ProcessWalks([]QueryItem{{Item: 0, Weight: 1}, {Item: 2, Weight: 1}})
+	if err != nil {
+		t.Fatalf("ProcessWalks: processing raised an error: %v", err)
+	}
+	if len(walks) != cfg.Draws {
+		t.Errorf("ProcessWalks: expected %d walks, got %d", cfg.Draws, len(walks))
+	}
+
+	for _, walk := range walks {
+		if walk.Origin != 0 && walk.Origin != 2 {
+			t.Errorf("ProcessWalks: walk started from item %d which is not in the query", walk.Origin)
+		}
+		if len(walk.Steps) != cfg.Depth {
+			t.Errorf("ProcessWalks: expected walks of depth %d, got %d", cfg.Depth, len(walk.Steps))
+			continue
+		}
+		item := walk.Origin
+		for d, step := range walk.Steps {
+			if !contains(bird.ItemsToUsers[item], step.Referrer) {
+				t.Errorf("ProcessWalks: at depth %d user %d did not interact with item %d", d+1, step.Referrer, item)
+			}
+			if !contains(bird.UsersToItems[step.Referrer], step.Item) {
+				t.Errorf("ProcessWalks: at depth %d item %d is not in user %d's collection", d+1, step.Item, step.Referrer)
+			}
+			item = step.Item
+		}
+	}
+}
+
+type SampleQueryCase struct {
+	Name    string
+	Query   []QueryItem
+	Dropped []int
+	Valid   bool
+}
+
+var sampleQueryTable = []SampleQueryCase{
+	{
+		Name:    "Isolated items are dropped",
+		Query:   []QueryItem{{Item: 0, Weight: 1}, {Item: 2, Weight: 10}, {Item: 1, Weight: 1}},
+		Dropped: []int{2},
+		Valid:   true,
+	},
+	{
+		Name:    "Only isolated items",
+		Query:   []QueryItem{{Item: 2, Weight: 1}, {Item: 3, Weight: 1}},
+		Dropped: []int{},
+		Valid:   false,
+	},
+	{
+		Name:    "Item out of range",
+		Query:   []QueryItem{{Item: 0, Weight: 1}, {Item: 4, Weight: 1}},
+		Dropped: []int{},
+		Valid:   false,
+	},
+	{
+		Name:    "Negative item",
+		Query:   []QueryItem{{Item: -1, Weight: 1}},
+		Dropped: []int{},
+		Valid:   false,
+	},
+}
+
+func TestBirdSampleItemsFromQuery(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.Draws = 100
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1}, [][]int{{0, 1}, {1}})
+	if err != nil {
+		t.Fatalf("SampleItemsFromQuery: Bird initialization raised an error: %v", err)
+	}
+
+	for _, ex := range sampleQueryTable {
+		items, dropped, err := bird.sampleItemsFromQuery(ex.Query)
+		if err != nil && ex.Valid {
+			t.Errorf("SampleItemsFromQuery: %s: sampling should not have raised "+
+				"an error but did: %v", ex.Name, err)
+			continue
+		}
+		if err == nil && !ex.Valid {
+			t.Errorf("SampleItemsFromQuery: %s: sampling should have raised "+
+				"an error but did not", ex.Name)
+			continue
+		}
+
+		if len(dropped) != len(ex.Dropped) {
+			t.Errorf("SampleItemsFromQuery: %s: expected %d dropped items, got %d", ex.Name, len(ex.Dropped), len(dropped))
+			continue
+		}
+		for i, q := range dropped {
+			if q.Item != ex.Dropped[i] {
+				t.Errorf("SampleItemsFromQuery: %s: expected %v to be dropped, got %v", ex.Name, ex.Dropped, dropped)
+				break
+			}
+		}
+		for _, item := range items {
+			if len(bird.ItemsToUsers[item]) == 0 {
+				t.Errorf("SampleItemsFromQuery: %s: sampled item %d no one has interacted with", ex.Name, item)
+				break
+			}
+		}
+	}
+
+	_, _, err = bird.sampleItemsFromQuery([]QueryItem{{Item: 7, Weight: 1}})
+	var unknownItemErr *UnknownItemError
+	if !errors.As(err, &unknownItemErr) || unknownItemErr.Item != 7 {
+		t.Errorf("SampleItemsFromQuery: expected an UnknownItemError for item 7, got %v", err)
+	}
+}
+
+func contains(list []int, value int) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func benchmarkBirdSampleItemsFromQuery(querySize, numItems int, b *testing.B) {
 	query := make([]QueryItem, querySize)
 	for i := 0; i < querySize; i++ {
@@ -128,7 +259,7 @@ func benchmarkBirdSampleItemsFromQuery(querySize, numItems int, b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = bird.sampleItemsFromQuery(query)
+		_, _, _ = bird.sampleItemsFromQuery(query)
 	}
 }
 
@@ -33,9 +33,83 @@
 // the visitedItems and referrers are then used to produce recommendations.
 // Note that Bird does not support empty queries.
 //
+// Process flattens the random walks. To know which query item a visit
+// originates from and at which depth it happened, use
+//
+// 	walks, dropped, err := charlie.ProcessWalks(query)
+//
+// which returns the full path of every walk.
+//
 // It is possible (although not desirable) that an item in the query refers to
 // an item no one has interacted with. We ignore said item for the rest of the
-// calculations.
+// calculations; ProcessWalks reports the query items that were dropped. Items
+// that do not belong to the graph at all return an UnknownItemError.
+//
+// The errors returned by the engines wrap exported sentinels such as
+// ErrEmptyQuery, ErrUnknownItem or ErrInvalidConfig, which can be checked with
+// errors.Is.
+//
+// The engines can also be built with New, which takes functional options for
+// the interactions (weighted or not), the item weights or the policy to derive
+// them, the social graph, the walk mode and the random seed:
+//
+// 	engine, err := New(WithInteractions(usersToItems),
+// 		WithItemWeightPolicy(InversePopularityItemWeights), WithSeed(42))
+//
+// To serve a known user, RecommendForUser builds the query from their own
+// history, limited by the HistoryLimit and HistoryOrder settings, and leaves
+// out the items they already interacted with. Conversely, RecommendAudience
+// finds the users most likely to engage with an item they do not have yet.
+// RecommendForGroup serves several users at once, aggregating their
+// satisfaction on average, by least misery or by most pleasure.
+//
+// To avoid lists of near-identical items, RerankMMR diversifies scored
+// candidates such as those returned by ScoreMostVisited, using for instance
+// the CooccurrenceSimilarity of the items.
+//
+// Business rules such as "exclude explicit=true", "require region=FR" or
+// "max 3 per label" are read by ParseRule and attached to the engine along
+// with an AttributeStore of the items' attributes:
+//
+// 	rules, err := ParseRules("exclude explicit=true", "max 3 per label")
+// 	engine, err := New(WithInteractions(usersToItems), WithAttributes(store, rules...))
+//
+// The items recommended by RecommendForUser, RecommendForGroup and
+// SimilarItems then comply with the rules, and ApplyRules applies them to the
+// output of the other recommenders, such as RecommendItems or RerankMMR.
+//
+// Engines built with WithTimedInteractions and the TemporalWalk mode perform
+// walks that only move forward in time: from an item, the walker moves to
+// one of the items a user interacted with right after it, within
+// BirdCfg.Window. Starting from the tracks of a listening session, they
+// predict what comes next.
+//
+// Session builds such queries in real time from a stream of plays, likes and
+// skips, the most recent events weighing the most. Skips make up a separate
+// negative query.
+//
+// Query weights cannot be negative. Instead, WalkFeedback launches separate
+// walks from the disliked items, and RecommendFeedback and
+// RecommendTrustFeedback penalize the items and the referrers these negative
+// walks visit:
+//
+// 	query, negative := session.Query(time.Now())
+// 	positiveWalks, negativeWalks, err := WalkFeedback(engine, query, negative, user)
+// 	recommended := RecommendFeedback(positiveWalks, negativeWalks, 1)
+//
+// Radio generates an endless stream of items from a seed query, without
+// repeating recent items or groups of items such as artists, and folds the
+// emitted items back into the query so the stream slowly drifts.
+//
+// Recommend never leaves a new user or a query of new items empty-handed:
+// when the walks cannot be performed, it tries the fallbacks listed in
+// BirdCfg.Fallbacks in order, such as the most popular items or the items of
+// the user's friends, and reports the Source of the recommended items.
+//
+// An Ensemble sends the same query to several engines concurrently, for
+// instance a Bird built on plays and a Weaver built on follows, and blends
+// their outputs with weights or by reciprocal rank fusion. Engines that fail
+// or time out are left out of the blend.
 //
 // Use cases are recommendations based on a item/container bipartite graph. For
 // instance: - Recommend new artists/songs based on user-item relationships; -
@@ -64,5 +138,6 @@
 // where `usersToItems` is now of type `[]map[int]float64`. `mingus` is now of
 // type `*Bird`. The only technical difference between Bird and Emu in the code
 // is the way we initialize samplers for each users; Bird and Emu share
-// everything else.
+// everything else. Similarly, NewWeightedWeaver builds a Weaver on top of an
+// Emu to combine weighted interactions with social weighting.
 package birdland
@@ -0,0 +1,224 @@
+package birdland
+
+import (
+	"container/list"
+	"sync"
+)
+
+// indexSampler samples indices from a discrete probability distribution.
+type indexSampler interface {
+	Sample(numSamples int) []int
+}
+
+// samplerKey identifies the sampler of the users related to Item, weighted
+// from the point of view of the served User.
+type samplerKey struct {
+	User int
+	Item int
+}
+
+type samplerEntry struct {
+	key     samplerKey
+	sampler indexSampler
+}
+
+// samplerCache is a least-recently-used cache of the social-weighted samplers
+// Weaver builds for each (served user, item) pair, so they are not rebuilt on
+// every step of every query. Entries are indexed by user so they can be
+// invalidated when the user's connections change.
+type samplerCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // most recently used entries at the front
+	entries  map[samplerKey]*list.Element
+	byUser   map[int]map[int]*list.Element
+}
+
+// newSamplerCache returns a cache that holds at most capacity samplers. A
+// capacity lower than 1 returns a nil cache, which caches nothing.
+func newSamplerCache(capacity int) *samplerCache {
+	if capacity < 1 {
+		return nil
+	}
+
+	return &samplerCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[samplerKey]*list.Element),
+		byUser:   make(map[int]map[int]*list.Element),
+	}
+}
+
+// Get returns the sampler cached for the user and the item, if any.
+func (c *samplerCache) Get(user, item int) (indexSampler, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[samplerKey{user, item}]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+
+	return e.Value.(*samplerEntry).sampler, true
+}
+
+// Add caches the sampler for the user and the item, evicting the least
+// recently used sampler if the cache is full.
+func (c *samplerCache) Add(user, item int, s indexSampler) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := samplerKey{user, item}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*samplerEntry).sampler = s
+		c.order.MoveToFront(e)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		c.remove(c.order.Back())
+	}
+
+	e := c.order.PushFront(&samplerEntry{key, s})
+	c.entries[key] = e
+	if _, ok := c.byUser[user]; !ok {
+		c.byUser[user] = make(map[int]*list.Element)
+	}
+	c.byUser[user][item] = e
+}
+
+// InvalidateUser removes all the samplers built for the user.
+func (c *samplerCache) InvalidateUser(user int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range c.byUser[user] {
+		c.remove(e)
+	}
+}
+
+// Purge removes all the samplers from the cache.
+func (c *samplerCache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[samplerKey]*list.Element)
+	c.byUser = make(map[int]map[int]*list.Element)
+}
+
+// Len returns the number of samplers in the cache.
+func (c *samplerCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *samplerCache) remove(e *list.Element) {
+	key := e.Value.(*samplerEntry).key
+	c.order.Remove(e)
+	delete(c.entries, key)
+	delete(c.byUser[key.User], key.Item)
+	if len(c.byUser[key.User]) == 0 {
+		delete(c.byUser, key.User)
+	}
+}
+
+type connectionsEntry struct {
+	user        int
+	connections map[int]float64
+}
+
+// connectionsCache is a least-recently-used cache of the multi-hop social
+// neighbourhoods Weaver computes for the users it serves.
+type connectionsCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // most recently used entries at the front
+	entries  map[int]*list.Element
+}
+
+// newConnectionsCache returns a cache that holds at most capacity
+// neighbourhoods. A capacity lower than 1 returns a nil cache, which caches
+// nothing.
+func newConnectionsCache(capacity int) *connectionsCache {
+	if capacity < 1 {
+		return nil
+	}
+
+	return &connectionsCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[int]*list.Element),
+	}
+}
+
+// Get returns the neighbourhood cached for the user, if any.
+func (c *connectionsCache) Get(user int) (map[int]float64, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[user]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+
+	return e.Value.(*connectionsEntry).connections, true
+}
+
+// Add caches the neighbourhood of the user, evicting the least recently used
+// neighbourhood if the cache is full.
+func (c *connectionsCache) Add(user int, connections map[int]float64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[user]; ok {
+		e.Value.(*connectionsEntry).connections = connections
+		c.order.MoveToFront(e)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*connectionsEntry).user)
+	}
+
+	c.entries[user] = c.order.PushFront(&connectionsEntry{user, connections})
+}
+
+// Purge removes all the neighbourhoods from the cache.
+func (c *connectionsCache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[int]*list.Element)
+}
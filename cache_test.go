@@ -0,0 +1,56 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/rlouf/birdland/sampler"
+)
+
+func TestSamplerCacheEviction(t *testing.T) {
+	c := newSamplerCache(2)
+	s := &sampler.AliasSampler{}
+
+	c.Add(0, 0, s)
+	c.Add(0, 1, s)
+	if _, ok := c.Get(0, 0); !ok {
+		t.Errorf("SamplerCache: expected sampler (0, 0) to be cached")
+	}
+
+	// (0, 1) is now the least recently used entry
+	c.Add(1, 0, s)
+	if _, ok := c.Get(0, 1); ok {
+		t.Errorf("SamplerCache: expected sampler (0, 1) to be evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("SamplerCache: expected 2 cached samplers, got %d", c.Len())
+	}
+}
+
+func TestSamplerCacheInvalidation(t *testing.T) {
+	c := newSamplerCache(10)
+	s := &sampler.AliasSampler{}
+
+	c.Add(0, 0, s)
+	c.Add(0, 1, s)
+	c.Add(1, 0, s)
+	c.InvalidateUser(0)
+	if _, ok := c.Get(0, 0); ok {
+		t.Errorf("SamplerCache: expected sampler (0, 0) to be invalidated")
+	}
+	if _, ok := c.Get(1, 0); !ok {
+		t.Errorf("SamplerCache: expected sampler (1, 0) to survive the invalidation of user 0")
+	}
+
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("SamplerCache: expected an empty cache after purge, got %d samplers", c.Len())
+	}
+}
+
+func TestDisabledSamplerCache(t *testing.T) {
+	c := newSamplerCache(0)
+	c.Add(0, 0, &sampler.AliasSampler{})
+	if _, ok := c.Get(0, 0); ok {
+		t.Errorf("SamplerCache: a cache of size 0 should not cache anything")
+	}
+}
@@ -0,0 +1,148 @@
+package birdland
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Similarity is a measure of how close the collections of two users are.
+type Similarity int
+
+const (
+	SharedItems Similarity = iota // number of items the users have in common
+	Jaccard                       // shared items over the size of the union of the collections
+	Cosine                        // shared items over the geometric mean of the sizes of the collections
+)
+
+type SocialGraphCfg struct {
+	Similarity   Similarity `yaml:"similarity"`
+	Neighbours   int        `yaml:"neighbours"`     // number of connections kept for each user
+	MaxItemUsers int        `yaml:"max_item_users"` // items with more users are subsampled
+}
+
+func NewSocialGraphCfg() *SocialGraphCfg {
+	cfg := SocialGraphCfg{
+		Similarity:   Jaccard,
+		Neighbours:   50,
+		MaxItemUsers: 1000,
+	}
+
+	return &cfg
+}
+
+// BuildSocialGraph derives a sparse user-user graph that can be fed to Weaver
+// from the user-item interactions, for products that do not have an explicit
+// social graph. Each user is connected to the Neighbours users whose
+// collections are the most similar to theirs.
+//
+// Users are processed one at a time so that, besides the ItemsToUsers
+// adjacency list, memory only grows with the size of the resulting graph.
+// The users of items with more than MaxItemUsers users are subsampled, and the
+// number of shared items is scaled accordingly, so that popular items do not
+// make the computation quadratic in their number of users.
+func BuildSocialGraph(cfg *SocialGraphCfg, usersToItems [][]int) ([]map[int]float64, error) {
+	if cfg.Neighbours < 1 {
+		return nil, errors.Wrap(ErrInvalidConfig, "the number of neighbours must be greater than or equal to 1")
+	}
+	if cfg.MaxItemUsers < 1 {
+		return nil, errors.Wrap(ErrInvalidConfig, "the maximum number of users per item must be greater than or equal to 1")
+	}
+	if cfg.Similarity < SharedItems || cfg.Similarity > Cosine {
+		return nil, errors.Wrapf(ErrInvalidConfig, "unknown similarity %d", cfg.Similarity)
+	}
+	if len(usersToItems) == 0 {
+		return nil, errors.Wrap(ErrInvalidInput, "empty users to items adjacency table")
+	}
+
+	// Duplicated interactions would count several times as shared items.
+	collections := make([][]int, len(usersToItems))
+	numItems := 0
+	for u, userItems := range usersToItems {
+		seen := make(map[int]bool, len(userItems))
+		for _, item := range userItems {
+			if item < 0 {
+				return nil, errors.Wrapf(ErrInvalidInput, "user %d interacted with negative item %d", u, item)
+			}
+			if seen[item] {
+				continue
+			}
+			seen[item] = true
+			collections[u] = append(collections[u], item)
+			if item >= numItems {
+				numItems = item + 1
+			}
+		}
+	}
+	itemsToUsers := permuteAdjacencyList(numItems, collections)
+
+	randSource := rand.New(rand.NewSource(time.Now().UnixNano()))
+	socialGraph := make([]map[int]float64, len(collections))
+	for u, userItems := range collections {
+		shared := make(map[int]float64)
+		for _, item := range userItems {
+			fans := itemsToUsers[item]
+			if len(fans) <= cfg.MaxItemUsers {
+				for _, v := range fans {
+					shared[v]++
+				}
+				continue
+			}
+			scale := float64(len(fans)) / float64(cfg.MaxItemUsers)
+			for i := 0; i < cfg.MaxItemUsers; i++ {
+				shared[fans[randSource.Intn(len(fans))]] += scale
+			}
+		}
+		delete(shared, u)
+
+		neighbours := &scoredPairHeap{}
+		for v, count := range shared {
+			score := similarity(cfg.Similarity, count, len(userItems), len(collections[v]))
+			if neighbours.Len() < cfg.Neighbours {
+				heap.Push(neighbours, ScoredPair{v, score})
+			} else if score > neighbours.ScoredPairList[0].Score {
+				neighbours.ScoredPairList[0] = ScoredPair{v, score}
+				heap.Fix(neighbours, 0)
+			}
+		}
+
+		socialGraph[u] = make(map[int]float64, neighbours.Len())
+		for _, pair := range neighbours.ScoredPairList {
+			socialGraph[u][pair.Object] = pair.Score
+		}
+	}
+
+	return socialGraph, nil
+}
+
+// similarity computes the similarity between two collections of size
+// sizeA and sizeB that share the given number of items.
+func similarity(s Similarity, shared float64, sizeA, sizeB int) float64 {
+	switch s {
+	case Jaccard:
+		return shared / math.Max(float64(sizeA+sizeB)-shared, shared)
+	case Cosine:
+		return shared / math.Sqrt(float64(sizeA*sizeB))
+	default:
+		return shared
+	}
+}
+
+// scoredPairHeap is a min-heap of scored pairs used to keep the top scores.
+type scoredPairHeap struct {
+	ScoredPairList
+}
+
+func (h *scoredPairHeap) Push(x interface{}) {
+	h.ScoredPairList = append(h.ScoredPairList, x.(ScoredPair))
+}
+
+func (h *scoredPairHeap) Pop() interface{} {
+	n := len(h.ScoredPairList)
+	x := h.ScoredPairList[n-1]
+	h.ScoredPairList = h.ScoredPairList[:n-1]
+	return x
+}
@@ -0,0 +1,131 @@
+package birdland
+
+import (
+	"math"
+	"testing"
+)
+
+type SocialGraphCase struct {
+	Name         string
+	Similarity   Similarity
+	Neighbours   int
+	MaxItemUsers int
+	UsersToItems [][]int
+	Expected     []map[int]float64
+	Valid        bool
+}
+
+var socialGraphTable = []SocialGraphCase{
+	{
+		Name:         "Zero neighbours",
+		Similarity:   Jaccard,
+		Neighbours:   0,
+		MaxItemUsers: 10,
+		UsersToItems: [][]int{{0}, {0}},
+		Valid:        false,
+	},
+	{
+		Name:         "Empty UsersToItems",
+		Similarity:   Jaccard,
+		Neighbours:   1,
+		MaxItemUsers: 10,
+		UsersToItems: [][]int{},
+		Valid:        false,
+	},
+	{
+		Name:         "Jaccard with one neighbour",
+		Similarity:   Jaccard,
+		Neighbours:   1,
+		MaxItemUsers: 10,
+		UsersToItems: [][]int{{0, 1, 2}, {0, 1}, {2, 3}, {3}},
+		Expected:     []map[int]float64{{1: 2. / 3}, {0: 2. / 3}, {3: 0.5}, {2: 0.5}},
+		Valid:        true,
+	},
+	{
+		Name:         "Shared items ignore duplicated interactions",
+		Similarity:   SharedItems,
+		Neighbours:   2,
+		MaxItemUsers: 10,
+		UsersToItems: [][]int{{0, 0, 1, 2}, {0, 1}, {2, 3}, {4}},
+		Expected:     []map[int]float64{{1: 2, 2: 1}, {0: 2}, {0: 1}, {}},
+		Valid:        true,
+	},
+	{
+		Name:         "Cosine",
+		Similarity:   Cosine,
+		Neighbours:   5,
+		MaxItemUsers: 10,
+		UsersToItems: [][]int{{0, 1, 2, 3}, {0}},
+		Expected:     []map[int]float64{{1: 0.5}, {0: 0.5}},
+		Valid:        true,
+	},
+}
+
+func TestBuildSocialGraph(t *testing.T) {
+	for _, ex := range socialGraphTable {
+		cfg := NewSocialGraphCfg()
+		cfg.Similarity = ex.Similarity
+		cfg.Neighbours = ex.Neighbours
+		cfg.MaxItemUsers = ex.MaxItemUsers
+
+		socialGraph, err := BuildSocialGraph(cfg, ex.UsersToItems)
+		if err != nil && ex.Valid {
+			t.Errorf("BuildSocialGraph: %s: should not have raised an error but did: %v", ex.Name, err)
+			continue
+		}
+		if err == nil && !ex.Valid {
+			t.Errorf("BuildSocialGraph: %s: should have raised an error but did not", ex.Name)
+			continue
+		}
+		if !ex.Valid {
+			continue
+		}
+
+		if len(socialGraph) != len(ex.Expected) {
+			t.Errorf("BuildSocialGraph: %s: expected %d users, got %d", ex.Name, len(ex.Expected), len(socialGraph))
+			continue
+		}
+		for u, expected := range ex.Expected {
+			if len(socialGraph[u]) != len(expected) {
+				t.Errorf("BuildSocialGraph: %s: expected %v for user %d, got %v", ex.Name, expected, u, socialGraph[u])
+				continue
+			}
+			for v, w := range expected {
+				if math.Abs(socialGraph[u][v]-w) > 1e-9 {
+					t.Errorf("BuildSocialGraph: %s: expected %v for user %d, got %v", ex.Name, expected, u, socialGraph[u])
+					break
+				}
+			}
+		}
+	}
+}
+
+// Subsampling the users of popular items must not change the order of
+// magnitude of the number of shared items.
+func TestBuildSocialGraphSubsampling(t *testing.T) {
+	numUsers := 2000
+	usersToItems := make([][]int, numUsers)
+	for u := range usersToItems {
+		usersToItems[u] = []int{0}
+	}
+
+	cfg := NewSocialGraphCfg()
+	cfg.Similarity = SharedItems
+	cfg.Neighbours = 1
+	cfg.MaxItemUsers = 100
+
+	socialGraph, err := BuildSocialGraph(cfg, usersToItems)
+	if err != nil {
+		t.Fatalf("BuildSocialGraph: subsampling: raised an error: %v", err)
+	}
+	for u, connections := range socialGraph {
+		if len(connections) != 1 {
+			t.Fatalf("BuildSocialGraph: subsampling: expected 1 neighbour for user %d, got %d", u, len(connections))
+		}
+		for _, w := range connections {
+			if w < 1 || w > 100 {
+				t.Fatalf("BuildSocialGraph: subsampling: unexpected number of shared items %v", w)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package birdland
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimedInteraction is an interaction of a user with an item that happened at
+// a given time, for instance a play.
+type TimedInteraction struct {
+	Item   int
+	Weight float64
+	Time   time.Time
+}
+
+// DecayInteractions builds the weighted user-item graph expected by Emu from
+// timestamped interactions. The weight of each interaction is halved every
+// halfLife until now, and the decayed weights of a user's interactions with
+// the same item are summed. A zero half-life disables the decay.
+func DecayInteractions(usersToTimedItems [][]TimedInteraction, halfLife time.Duration, now time.Time) []map[int]float64 {
+	usersToWeightedItems := make([]map[int]float64, len(usersToTimedItems))
+	for u, interactions := range usersToTimedItems {
+		usersToWeightedItems[u] = make(map[int]float64, len(interactions))
+		for _, i := range interactions {
+			usersToWeightedItems[u][i.Item] += i.Weight * decayFactor(now.Sub(i.Time), halfLife)
+		}
+	}
+
+	return usersToWeightedItems
+}
+
+// Reweight decays the interaction weights of a weighted engine from the time
+// of the last update to now, and adds the interactions that happened in the
+// meantime. Decay scales all the weights of a user by the same factor and
+// leaves the distribution of their items unchanged, so only the samplers of
+// the users with new interactions are reset, reusing their tables. Weights
+// that were not built with WithTimedInteractions are considered up to date
+// on the first call. The engine is left unchanged when an error is returned,
+// for instance when the weights of a user would all vanish. Reweight must not
+// be called concurrently with walks.
+func (b *Bird) Reweight(now time.Time, newInteractions map[int][]TimedInteraction) error {
+	if b.InteractionWeights == nil {
+		return errors.Wrap(ErrInvalidConfig, "only weighted engines can be re-weighted")
+	}
+	for user, interactions := range newInteractions {
+		if user < 0 || user >= len(b.UsersToItems) {
+			return &UnknownUserError{User: user}
+		}
+		for _, i := range interactions {
+			if i.Item < 0 || i.Item >= len(b.ItemWeights) {
+				return &UnknownItemError{Item: i.Item}
+			}
+			if i.Weight < 0 {
+				return errors.Wrapf(ErrInvalidInput, "user %d has a negative weight for item %d", user, i.Item)
+			}
+		}
+	}
+
+	factor := 1.
+	if !b.WeightsUpdated.IsZero() {
+		factor = decayFactor(now.Sub(b.WeightsUpdated), b.Cfg.HalfLife)
+	}
+
+	users := make([]int, 0, len(newInteractions))
+	for user := range newInteractions {
+		users = append(users, user)
+	}
+	sort.Ints(users)
+
+	// The new items and weights of the users are computed and checked before
+	// anything is modified, so the engine is left unchanged on error.
+	items := make([][]int, len(users))
+	weights := make([][]float64, len(users))
+	for u, user := range users {
+		items[u] = append([]int(nil), b.UsersToItems[user]...)
+		weights[u] = make([]float64, len(b.InteractionWeights[user]), len(b.InteractionWeights[user])+len(newInteractions[user]))
+		for j, w := range b.InteractionWeights[user] {
+			weights[u][j] = w * factor
+		}
+		for _, i := range newInteractions[user] {
+			items[u], weights[u] = addInteraction(items[u], weights[u], i.Item, i.Weight*decayFactor(now.Sub(i.Time), b.Cfg.HalfLife))
+		}
+
+		var sum float64
+		for _, w := range weights[u] {
+			sum += w
+		}
+		if sum == 0 {
+			return errors.Wrapf(ErrInvalidInput, "user %d would be left without weighted interactions", user)
+		}
+	}
+
+	for _, w := range b.InteractionWeights {
+		for j := range w {
+			w[j] *= factor
+		}
+	}
+	b.WeightsUpdated = now
+
+	for u, user := range users {
+		for _, item := range items[u] {
+			fans := b.ItemsToUsers[item]
+			pos := sort.SearchInts(fans, user)
+			if pos == len(fans) || fans[pos] != user {
+				b.ItemsToUsers[item] = insertInt(fans, pos, user)
+			}
+		}
+		b.UsersToItems[user] = items[u]
+		b.InteractionWeights[user] = weights[u]
+		if b.timeline != nil {
+			b.timeline.add(user, newInteractions[user])
+		}
+		err := b.UserItemsSamplers[user].Reset(b.InteractionWeights[user])
+		if err != nil {
+			return errors.Wrapf(err, "cannot reset the sampler of user %d", user)
+		}
+	}
+
+	return nil
+}
+
+// addInteraction adds weight to the interaction with item in a user's sorted
+// items and their weights, inserting the item if needed.
+func addInteraction(items []int, weights []float64, item int, weight float64) ([]int, []float64) {
+	pos := sort.SearchInts(items, item)
+	if pos < len(items) && items[pos] == item {
+		weights[pos] += weight
+		return items, weights
+	}
+
+	return insertInt(items, pos, item), insertFloat64(weights, pos, weight)
+}
+
+// decayFactor returns the factor by which weights decay over the given age.
+// Interactions from the future are not boosted.
+func decayFactor(age, halfLife time.Duration) float64 {
+	if halfLife <= 0 || age <= 0 {
+		return 1
+	}
+
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+func insertInt(s []int, pos, v int) []int {
+	s = append(s, 0)
+	copy(s[pos+1:], s[pos:])
+	s[pos] = v
+
+	return s
+}
+
+func insertFloat64(s []float64, pos int, v float64) []float64 {
+	s = append(s, 0)
+	copy(s[pos+1:], s[pos:])
+	s[pos] = v
+
+	return s
+}
+
+// Reweight re-weights the interactions as Bird does. The samplers of related
+// users are purged since new interactions modify ItemsToUsers.
+func (b *Weaver) Reweight(now time.Time, newInteractions map[int][]TimedInteraction) error {
+	err := b.Bird.Reweight(now, newInteractions)
+	if err != nil {
+		return err
+	}
+	if len(newInteractions) > 0 {
+		b.PurgeSamplers()
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package birdland
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var decayNow = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+type DecayCase struct {
+	Name         string
+	HalfLife     time.Duration
+	Interactions []TimedInteraction
+	Expected     map[int]float64
+}
+
+var decay_table = []DecayCase{
+	{
+		Name:     "No decay",
+		HalfLife: 0,
+		Interactions: []TimedInteraction{
+			{Item: 0, Weight: 4, Time: decayNow.Add(-48 * time.Hour)},
+		},
+		Expected: map[int]float64{0: 4},
+	},
+	{
+		Name:     "Two half-lives",
+		HalfLife: 24 * time.Hour,
+		Interactions: []TimedInteraction{
+			{Item: 0, Weight: 4, Time: decayNow.Add(-48 * time.Hour)},
+		},
+		Expected: map[int]float64{0: 1},
+	},
+	{
+		Name:     "Interactions with the same item are summed",
+		HalfLife: 24 * time.Hour,
+		Interactions: []TimedInteraction{
+			{Item: 0, Weight: 4, Time: decayNow.Add(-48 * time.Hour)},
+			{Item: 1, Weight: 1, Time: decayNow},
+			{Item: 0, Weight: 2, Time: decayNow.Add(-24 * time.Hour)},
+		},
+		Expected: map[int]float64{0: 2, 1: 1},
+	},
+	{
+		Name:     "Interactions from the future are not boosted",
+		HalfLife: 24 * time.Hour,
+		Interactions: []TimedInteraction{
+			{Item: 0, Weight: 1, Time: decayNow.Add(time.Hour)},
+		},
+		Expected: map[int]float64{0: 1},
+	},
+}
+
+func TestDecayInteractions(t *testing.T) {
+	for _, ex := range decay_table {
+		weighted := DecayInteractions([][]TimedInteraction{ex.Interactions}, ex.HalfLife, decayNow)
+		if len(weighted) != 1 || len(weighted[0]) != len(ex.Expected) {
+			t.Errorf("DecayInteractions: %s: expected %v, got %v", ex.Name, ex.Expected, weighted)
+			continue
+		}
+		for item, w := range ex.Expected {
+			if math.Abs(weighted[0][item]-w) > 1e-9 {
+				t.Errorf("DecayInteractions: %s: expected %v, got %v", ex.Name, ex.Expected, weighted[0])
+				break
+			}
+		}
+	}
+}
+
+func TestReweight(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.HalfLife = time.Hour
+	usersToTimedItems := [][]TimedInteraction{
+		{{Item: 0, Weight: 1, Time: decayNow}, {Item: 2, Weight: 2, Time: decayNow}},
+		{{Item: 1, Weight: 1, Time: decayNow}},
+	}
+	engine, err := New(WithCfg(cfg), WithTimedInteractions(usersToTimedItems, decayNow), WithItemWeights([]float64{1, 1, 1}))
+	if err != nil {
+		t.Fatalf("Reweight: initialization raised an error: %v", err)
+	}
+	bird := engine.(*Bird)
+
+	// user 0 starts listening to item 1, long after their other interactions
+	later := decayNow.Add(20 * time.Hour)
+	err = bird.Reweight(later, map[int][]TimedInteraction{0: {{Item: 1, Weight: 1, Time: later}}})
+	if err != nil {
+		t.Fatalf("Reweight: raised an error: %v", err)
+	}
+
+	expectedItems := []int{0, 1, 2}
+	expectedWeights := []float64{math.Exp2(-20), 1, 2 * math.Exp2(-20)}
+	for i := range expectedItems {
+		if bird.UsersToItems[0][i] != expectedItems[i] || math.Abs(bird.InteractionWeights[0][i]-expectedWeights[i]) > 1e-12 {
+			t.Fatalf("Reweight: expected items %v with weights %v, got %v and %v", expectedItems, expectedWeights,
+				bird.UsersToItems[0], bird.InteractionWeights[0])
+		}
+	}
+	if math.Abs(bird.InteractionWeights[1][0]-math.Exp2(-20)) > 1e-12 {
+		t.Errorf("Reweight: the weights of user 1 should have decayed, got %v", bird.InteractionWeights[1])
+	}
+	if len(bird.ItemsToUsers[1]) != 2 || bird.ItemsToUsers[1][0] != 0 || bird.ItemsToUsers[1][1] != 1 {
+		t.Errorf("Reweight: expected users [0 1] for item 1, got %v", bird.ItemsToUsers[1])
+	}
+	for i := 0; i < 100; i++ {
+		if item := bird.sampleItem(0); item != 1 {
+			t.Fatalf("Reweight: the sampler should draw the recent item 1, drew %d", item)
+		}
+	}
+	if !bird.WeightsUpdated.Equal(later) {
+		t.Errorf("Reweight: expected the weights to be updated at %v, got %v", later, bird.WeightsUpdated)
+	}
+
+	if err := bird.Reweight(later, map[int][]TimedInteraction{2: nil}); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("Reweight: expected an unknown user error, got %v", err)
+	}
+	if err := bird.Reweight(later, map[int][]TimedInteraction{0: {{Item: 3, Weight: 1, Time: later}}}); !errors.Is(err, ErrUnknownItem) {
+		t.Errorf("Reweight: expected an unknown item error, got %v", err)
+	}
+
+	// the weights of user 1 all vanish: the update of user 0 must not happen
+	items := append([]int(nil), bird.UsersToItems[0]...)
+	weights := append([]float64(nil), bird.InteractionWeights[0]...)
+	fans := append([]int(nil), bird.ItemsToUsers[2]...)
+	muchLater := later.Add(5000 * time.Hour)
+	err = bird.Reweight(muchLater, map[int][]TimedInteraction{
+		0: {{Item: 2, Weight: 1, Time: muchLater}},
+		1: {{Item: 0, Weight: 0, Time: muchLater}},
+	})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Reweight: expected an invalid input error, got %v", err)
+	}
+	if !reflect.DeepEqual(bird.UsersToItems[0], items) || !reflect.DeepEqual(bird.InteractionWeights[0], weights) {
+		t.Errorf("Reweight: expected items %v with weights %v after a failed update, got %v and %v", items, weights,
+			bird.UsersToItems[0], bird.InteractionWeights[0])
+	}
+	if len(bird.UsersToItems[1]) != 1 || !reflect.DeepEqual(bird.ItemsToUsers[2], fans) {
+		t.Errorf("Reweight: the adjacency lists should not change after a failed update")
+	}
+	if !bird.WeightsUpdated.Equal(later) {
+		t.Errorf("Reweight: expected the weights to be updated at %v after a failed update, got %v", later, bird.WeightsUpdated)
+	}
+
+	unweighted, err := NewBird(NewBirdCfg(), []float64{1, 1}, [][]int{{0}, {1}})
+	if err != nil {
+		t.Fatalf("Reweight: Bird initialization raised an error: %v", err)
+	}
+	if err := unweighted.Reweight(later, nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Reweight: expected an invalid configuration error for an unweighted graph, got %v", err)
+	}
+}
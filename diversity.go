@@ -0,0 +1,130 @@
+package birdland
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ItemSimilarity returns how similar two items are, between 0 and 1.
+type ItemSimilarity func(a, b int) float64
+
+// CooccurrenceSimilarity returns the similarity of two items measured by the
+// overlap of the sets of users who interacted with them, as given by
+// ItemsToUsers. With SharedItems, the number of shared users is divided by
+// the size of the smallest set so that the similarity lies between 0 and 1.
+func (b *Bird) CooccurrenceSimilarity(s Similarity) ItemSimilarity {
+	return func(x, y int) float64 {
+		fansX, fansY := b.ItemsToUsers[x], b.ItemsToUsers[y]
+		if len(fansX) == 0 || len(fansY) == 0 {
+			return 0
+		}
+
+		// ItemsToUsers lists users by ascending id.
+		var shared int
+		for i, j := 0, 0; i < len(fansX) && j < len(fansY); {
+			switch {
+			case fansX[i] < fansY[j]:
+				i++
+			case fansX[i] > fansY[j]:
+				j++
+			default:
+				shared++
+				i++
+				j++
+			}
+		}
+
+		if s == SharedItems {
+			// normalize the number of shared users by the smallest audience
+			smallest := len(fansX)
+			if len(fansY) < smallest {
+				smallest = len(fansY)
+			}
+			return float64(shared) / float64(smallest)
+		}
+
+		return similarity(s, float64(shared), len(fansX), len(fansY))
+	}
+}
+
+// ScoreMostVisited returns the objects and their number of visits, in
+// descending order of visits. It is the scored counterpart of
+// RecommendMostVisited, to be fed to RerankMMR.
+func ScoreMostVisited(items []int) ScoredPairList {
+	counts := make(map[int]float64)
+	for _, item := range items {
+		counts[item]++
+	}
+
+	scored := make(ScoredPairList, 0, len(counts))
+	for item, count := range counts {
+		scored = append(scored, ScoredPair{item, count})
+	}
+	sort.Sort(sort.Reverse(scored))
+
+	return scored
+}
+
+// RerankMMR selects k items among the scored candidates with Maximal Marginal
+// Relevance: items are picked greedily, each time maximizing
+//
+//	lambda * relevance - (1 - lambda) * max similarity to the picked items
+//
+// where relevance is the candidate's score divided by the highest score.
+// lambda must lie between 0 and 1 and k must not be negative, otherwise
+// ErrInvalidConfig is returned. With lambda = 1 the candidates are simply
+// sorted by score, while lower values trade relevance for diversity so the
+// top of the list is not made of near-identical items.
+func RerankMMR(candidates ScoredPairList, sim ItemSimilarity, lambda float64, k int) ([]int, error) {
+	if lambda < 0 || lambda > 1 {
+		return nil, errors.Wrapf(ErrInvalidConfig, "lambda must lie between 0 and 1, got %v", lambda)
+	}
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+
+	var maxScore float64
+	for _, c := range candidates {
+		if c.Score > maxScore {
+			maxScore = c.Score
+		}
+	}
+
+	remaining := make(ScoredPairList, len(candidates))
+	copy(remaining, candidates)
+	maxSimilarity := make([]float64, len(remaining))
+
+	if k > len(remaining) {
+		k = len(remaining)
+	}
+	selected := make([]int, 0, k)
+	for len(selected) < k {
+		best := 0
+		bestValue := 0.0
+		for i, c := range remaining {
+			relevance := 0.0
+			if maxScore > 0 {
+				relevance = c.Score / maxScore
+			}
+			value := lambda*relevance - (1-lambda)*maxSimilarity[i]
+			if i == 0 || value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+
+		picked := remaining[best].Object
+		selected = append(selected, picked)
+
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		maxSimilarity = append(maxSimilarity[:best], maxSimilarity[best+1:]...)
+		for i, c := range remaining {
+			if s := sim(picked, c.Object); s > maxSimilarity[i] {
+				maxSimilarity[i] = s
+			}
+		}
+	}
+
+	return selected, nil
+}
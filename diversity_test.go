@@ -0,0 +1,101 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCooccurrenceSimilarity(t *testing.T) {
+	// item 0 has users {0, 1, 2}, item 1 has users {1, 2}, item 2 has user {3}
+	bird, err := NewBird(NewBirdCfg(), []float64{1, 1, 1, 1}, [][]int{{0}, {0, 1}, {1, 0}, {2}})
+	if err != nil {
+		t.Fatalf("CooccurrenceSimilarity: Bird initialization raised an error: %v", err)
+	}
+
+	cases := []struct {
+		Similarity Similarity
+		A, B       int
+		Expected   float64
+	}{
+		{Jaccard, 0, 1, 2.0 / 3},
+		{Jaccard, 1, 0, 2.0 / 3},
+		{SharedItems, 0, 1, 1},
+		{Jaccard, 0, 2, 0},
+		{Jaccard, 0, 3, 0},
+	}
+	for _, ex := range cases {
+		got := bird.CooccurrenceSimilarity(ex.Similarity)(ex.A, ex.B)
+		if got != ex.Expected {
+			t.Errorf("CooccurrenceSimilarity: similarity %d of items %d and %d: expected %v, got %v",
+				ex.Similarity, ex.A, ex.B, ex.Expected, got)
+		}
+	}
+}
+
+func TestScoreMostVisited(t *testing.T) {
+	scored := ScoreMostVisited([]int{1, 2, 2, 3, 2, 3})
+	expected := ScoredPairList{{2, 3}, {3, 2}, {1, 1}}
+	if len(scored) != len(expected) {
+		t.Fatalf("ScoreMostVisited: expected %v, got %v", expected, scored)
+	}
+	for i := range expected {
+		if scored[i] != expected[i] {
+			t.Errorf("ScoreMostVisited: expected %v, got %v", expected, scored)
+			break
+		}
+	}
+}
+
+func TestRerankMMR(t *testing.T) {
+	// items 0, 1 and 2 are near duplicates, item 3 is different
+	candidates := ScoredPairList{{0, 10}, {1, 9}, {2, 8}, {3, 5}}
+	sim := func(a, b int) float64 {
+		if a < 3 && b < 3 {
+			return 0.9
+		}
+		return 0
+	}
+
+	cases := []struct {
+		Name     string
+		Lambda   float64
+		K        int
+		Expected []int // nil when the parameters are invalid
+	}{
+		{"Relevance only", 1, 3, []int{0, 1, 2}},
+		{"Relevance and diversity", 0.5, 3, []int{0, 3, 1}},
+		{"More items than candidates", 0.5, 10, []int{0, 3, 1, 2}},
+		{"No items", 0.5, 0, []int{}},
+		{"Negative lambda", -0.1, 3, nil},
+		{"Lambda above 1", 1.1, 3, nil},
+		{"Negative k", 0.5, -1, nil},
+	}
+	for _, ex := range cases {
+		reranked, err := RerankMMR(candidates, sim, ex.Lambda, ex.K)
+		if ex.Expected == nil {
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("RerankMMR: %s: expected an invalid configuration error, got %v", ex.Name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("RerankMMR: %s: should not have raised an error but did: %v", ex.Name, err)
+			continue
+		}
+		if len(reranked) != len(ex.Expected) {
+			t.Errorf("RerankMMR: %s: expected %v, got %v", ex.Name, ex.Expected, reranked)
+			continue
+		}
+		for i := range reranked {
+			if reranked[i] != ex.Expected[i] {
+				t.Errorf("RerankMMR: %s: expected %v, got %v", ex.Name, ex.Expected, reranked)
+				break
+			}
+		}
+	}
+
+	if candidates[1] != (ScoredPair{1, 9}) {
+		t.Errorf("RerankMMR: the candidates should not be modified, got %v", candidates)
+	}
+}
@@ -2,7 +2,7 @@ package birdland
 
 import (
 	"math/rand"
-	"time"
+	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/rlouf/birdland/sampler"
@@ -11,35 +11,33 @@ import (
 // NewEmu creates a new recommender from input data. Unlike Bird, the
 // user-to-item bipartite graph is a weighted graph.
 func NewEmu(cfg *BirdCfg, itemWeights []float64, usersToWeightedItems []map[int]float64) (*Bird, error) {
-	if cfg.Depth < 1 {
-		return nil, errors.New("depth must be greater or equal to 1")
+	engine, err := New(WithCfg(cfg), WithItemWeights(itemWeights), WithWeightedInteractions(usersToWeightedItems))
+	if err != nil {
+		return nil, err
 	}
 
-	if cfg.Draws < 1 {
-		return nil, errors.New("number of draws must be greater or equal to 1")
-	}
+	return engine.(*Bird), nil
+}
 
-	randSource := rand.New(rand.NewSource(time.Now().UnixNano()))
+// newEmu creates a new recommender from validated input data.
+func newEmu(cfg *BirdCfg, randSource *rand.Rand, itemWeights []float64,
+	usersToWeightedItems []map[int]float64) (*Bird, error) {
 
-	err := validateEmuInputs(itemWeights, usersToWeightedItems)
+	userItemsSampler, usersToItems, interactionWeights, err := initUserWeightedItemsSamplers(randSource, usersToWeightedItems)
 	if err != nil {
-		return &Bird{}, errors.Wrap(err, "invalid input")
-	}
-
-	userItemsSampler, usersToItems, err := initUserWeightedItemsSamplers(randSource, usersToWeightedItems)
-	if err != nil {
-		return &Bird{}, errors.Wrap(err, "cannot initialize samplers")
+		return nil, errors.Wrap(err, "cannot initialize samplers")
 	}
 
 	itemsToUsers := permuteAdjacencyList(len(itemWeights), usersToItems)
 
 	b := Bird{
-		Cfg:               cfg,
-		RandSource:        randSource,
-		ItemWeights:       itemWeights,
-		UsersToItems:      usersToItems,
-		ItemsToUsers:      itemsToUsers,
-		UserItemsSamplers: userItemsSampler,
+		Cfg:                cfg,
+		RandSource:         randSource,
+		ItemWeights:        itemWeights,
+		UsersToItems:       usersToItems,
+		ItemsToUsers:       itemsToUsers,
+		InteractionWeights: interactionWeights,
+		UserItemsSamplers:  userItemsSampler,
 	}
 
 	return &b, nil
@@ -50,30 +48,35 @@ func NewEmu(cfg *BirdCfg, itemWeights []float64, usersToWeightedItems []map[int]
 // better in benchmarks.
 // We also concurrently create the usersToItems slice of slice since the way
 // items are ordered in the slice corresponding to each user must match the
-// order of the weights used to initialize the corresponding sampler.
+// order of the weights used to initialize the corresponding sampler. Items
+// are sorted so that seeded engines perform the same walks.
 func initUserWeightedItemsSamplers(randSource *rand.Rand,
-	usersToWeightedItems []map[int]float64) ([]sampler.AliasSampler, [][]int, error) {
+	usersToWeightedItems []map[int]float64) ([]sampler.AliasSampler, [][]int, [][]float64, error) {
 
 	usersToItems := make([][]int, len(usersToWeightedItems))
+	interactionWeights := make([][]float64, len(usersToWeightedItems))
 	userItemsSamplers := make([]sampler.AliasSampler, len(usersToWeightedItems))
 	for i, userItems := range usersToWeightedItems {
-		usersToItems[i] = make([]int, len(userItems))
+		usersToItems[i] = make([]int, 0, len(userItems))
+		for item := range userItems {
+			usersToItems[i] = append(usersToItems[i], item)
+		}
+		sort.Ints(usersToItems[i])
+
 		weights := make([]float64, len(userItems))
-		j := 0
-		for item, w := range userItems {
-			usersToItems[i][j] = item
-			weights[j] = w
-			j++
+		for j, item := range usersToItems[i] {
+			weights[j] = userItems[item]
 		}
 
 		userItemsSampler, err := sampler.NewAliasSampler(randSource, weights)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "could not initialize the probability and alias tables")
+			return nil, nil, nil, errors.Wrap(err, "could not initialize the probability and alias tables")
 		}
 		userItemsSamplers[i] = *userItemsSampler
+		interactionWeights[i] = weights
 	}
 
-	return userItemsSamplers, usersToItems, nil
+	return userItemsSamplers, usersToItems, interactionWeights, nil
 }
 
 // validateEmuInput checks the validity of the data fed to a weighted Bird.  It returns
@@ -82,10 +85,10 @@ func initUserWeightedItemsSamplers(randSource *rand.Rand,
 func validateEmuInputs(itemWeights []float64, usersToWeightedItems []map[int]float64) error {
 
 	if len(itemWeights) == 0 {
-		return errors.New("empty slice of item weights")
+		return errors.Wrap(ErrInvalidInput, "empty slice of item weights")
 	}
 	if len(usersToWeightedItems) == 0 {
-		return errors.New("empty users to items adjacency table")
+		return errors.Wrap(ErrInvalidInput, "empty users to items adjacency table")
 	}
 
 	// Check that there is a weight for each item present in adjacency tables.
@@ -94,7 +97,7 @@ func validateEmuInputs(itemWeights []float64, usersToWeightedItems []map[int]flo
 	for _, userItems := range usersToWeightedItems {
 		for item, w := range userItems {
 			if w < 0 {
-				return errors.New("there is a negative weight in usersToWeightedItems")
+				return errors.Wrap(ErrInvalidInput, "there is a negative weight in usersToWeightedItems")
 			}
 			if item > m {
 				m = item
@@ -102,7 +105,7 @@ func validateEmuInputs(itemWeights []float64, usersToWeightedItems []map[int]flo
 		}
 	}
 	if numItems <= m {
-		return errors.New("there are more items in UsersToItems than there are weights")
+		return errors.Wrap(ErrInvalidInput, "UsersToItems references more items than itemWeights")
 	}
 
 	return nil
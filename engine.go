@@ -0,0 +1,372 @@
+package birdland
+
+import (
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Engine is the interface shared by the recommenders built with New. The user
+// being served is ignored by engines that do not personalize the walks.
+type Engine interface {
+	Walk(query []QueryItem, user int) (Walks, []QueryItem, error)
+}
+
+// Walk performs random walks starting from items sampled from the query. The
+// user is ignored since Bird's walks do not depend on the user being served.
+func (b *Bird) Walk(query []QueryItem, user int) (Walks, []QueryItem, error) {
+	return b.ProcessWalks(query)
+}
+
+// Walk performs random walks on behalf of user starting from items sampled
+// from the query.
+func (b *Weaver) Walk(query []QueryItem, user int) (Walks, []QueryItem, error) {
+	return b.ProcessWalks(query, user)
+}
+
+// WalkMode determines which edges the random walks traverse.
+type WalkMode int
+
+const (
+	ItemWalk     WalkMode = iota // walks alternate between items and the users who interacted with them
+	SocialWalk                   // walks may also follow the edges of the social graph, see WeaverCfg.SocialJump
+	TemporalWalk                 // walks only move forward in time along each user's history, see BirdCfg.Window
+)
+
+// ItemWeightPolicy computes the global weight of each item from the number
+// of users who interacted with it.
+type ItemWeightPolicy func(degrees []int) []float64
+
+// UniformItemWeights gives the same weight to every item.
+func UniformItemWeights(degrees []int) []float64 {
+	weights := make([]float64, len(degrees))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	return weights
+}
+
+// PopularityItemWeights weighs items by their number of users.
+func PopularityItemWeights(degrees []int) []float64 {
+	weights := make([]float64, len(degrees))
+	for i, d := range degrees {
+		weights[i] = float64(d)
+	}
+
+	return weights
+}
+
+// InversePopularityItemWeights weighs items by the inverse of their number of
+// users, which favours the long tail.
+func InversePopularityItemWeights(degrees []int) []float64 {
+	weights := make([]float64, len(degrees))
+	for i, d := range degrees {
+		if d > 0 {
+			weights[i] = 1 / float64(d)
+		}
+	}
+
+	return weights
+}
+
+type options struct {
+	cfg                  *BirdCfg
+	weaverCfg            *WeaverCfg
+	itemWeights          []float64
+	itemWeightPolicy     ItemWeightPolicy
+	usersToItems         [][]int
+	usersToWeightedItems []map[int]float64
+	usersToTimedItems    [][]TimedInteraction
+	now                  time.Time
+	socialGraph          []map[int]float64
+	seed                 int64
+	seeded               bool
+	walkMode             WalkMode
+	attributes           *AttributeStore
+	rules                []Rule
+}
+
+// Option configures the engine built by New.
+type Option func(*options)
+
+// WithCfg sets the depth and number of draws of the walks. Defaults to
+// NewBirdCfg().
+func WithCfg(cfg *BirdCfg) Option {
+	return func(o *options) { o.cfg = cfg }
+}
+
+// WithInteractions builds the engine on an unweighted user-item graph.
+func WithInteractions(usersToItems [][]int) Option {
+	return func(o *options) { o.usersToItems = usersToItems }
+}
+
+// WithWeightedInteractions builds the engine on a weighted user-item graph,
+// as Emu.
+func WithWeightedInteractions(usersToWeightedItems []map[int]float64) Option {
+	return func(o *options) { o.usersToWeightedItems = usersToWeightedItems }
+}
+
+// WithTimedInteractions builds the engine on the weighted user-item graph
+// obtained by decaying the weights of timestamped interactions until now,
+// with the half-life set in the configuration. See DecayInteractions and
+// Reweight.
+func WithTimedInteractions(usersToTimedItems [][]TimedInteraction, now time.Time) Option {
+	return func(o *options) {
+		o.usersToTimedItems = usersToTimedItems
+		o.now = now
+	}
+}
+
+// WithItemWeights sets the global weight of each item.
+func WithItemWeights(itemWeights []float64) Option {
+	return func(o *options) { o.itemWeights = itemWeights }
+}
+
+// WithItemWeightPolicy computes the global weight of each item from the
+// user-item graph. It cannot be combined with WithItemWeights. Defaults to
+// UniformItemWeights when no item weights are given.
+func WithItemWeightPolicy(policy ItemWeightPolicy) Option {
+	return func(o *options) { o.itemWeightPolicy = policy }
+}
+
+// WithSocialGraph builds a Weaver that samples referrers according to the
+// social graph. cfg holds the social parameters and defaults to
+// NewWeaverCfg() if nil; if its BirdCfg is nil it is set by WithCfg.
+func WithSocialGraph(socialGraph []map[int]float64, cfg *WeaverCfg) Option {
+	return func(o *options) {
+		o.socialGraph = socialGraph
+		o.weaverCfg = cfg
+	}
+}
+
+// WithSeed seeds the random source of the engine to make the walks
+// reproducible. Defaults to the current time.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+		o.seeded = true
+	}
+}
+
+// WithWalkMode sets the edges the walks traverse. Defaults to ItemWalk.
+func WithWalkMode(mode WalkMode) Option {
+	return func(o *options) { o.walkMode = mode }
+}
+
+// WithAttributes attaches the attributes of the items to the engine, along
+// with the business rules the recommended items must comply with.
+func WithAttributes(store *AttributeStore, rules ...Rule) Option {
+	return func(o *options) {
+		o.attributes = store
+		o.rules = rules
+	}
+}
+
+// New builds a recommendation engine from the options. It returns a *Bird
+// when given a user-item graph, and a *Weaver when also given a social graph.
+// Unsupported combinations of options return an error that matches
+// ErrInvalidConfig.
+func New(opts ...Option) (Engine, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := o.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	var randSource *rand.Rand
+	if o.seeded {
+		randSource = rand.New(rand.NewSource(o.seed))
+	} else {
+		randSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	var bird *Bird
+	if o.usersToWeightedItems != nil {
+		bird, err = newEmu(o.cfg, randSource, o.itemWeights, o.usersToWeightedItems)
+	} else {
+		bird, err = newBird(o.cfg, randSource, o.itemWeights, o.usersToItems)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bird.Attributes = o.attributes
+	bird.WeightsUpdated = o.now
+	if o.walkMode == TemporalWalk {
+		bird.timeline = newTimeline(len(o.itemWeights), o.usersToTimedItems, o.cfg.Window)
+	}
+	bird.Rules = o.rules
+
+	if o.socialGraph == nil {
+		return bird, nil
+	}
+
+	return newWeaver(o.weaverCfg, bird, o.socialGraph), nil
+}
+
+// resolve checks that the options are compatible, fills in the defaults and
+// validates the configuration and input data.
+func (o *options) resolve() error {
+	if o.usersToTimedItems != nil && (o.usersToItems != nil || o.usersToWeightedItems != nil) {
+		return errors.Wrap(ErrInvalidConfig, "timed interactions cannot be combined with other interactions")
+	}
+	if o.usersToItems != nil && o.usersToWeightedItems != nil {
+		return errors.Wrap(ErrInvalidConfig, "weighted and unweighted interactions are mutually exclusive")
+	}
+	if o.usersToItems == nil && o.usersToWeightedItems == nil && o.usersToTimedItems == nil {
+		return errors.Wrap(ErrInvalidConfig, "no user-item interactions were provided")
+	}
+	if o.itemWeights != nil && o.itemWeightPolicy != nil {
+		return errors.Wrap(ErrInvalidConfig, "item weights and an item weight policy are mutually exclusive")
+	}
+
+	// Work on a copy so the caller's social configuration can be reused.
+	if o.weaverCfg != nil {
+		weaverCfg := *o.weaverCfg
+		o.weaverCfg = &weaverCfg
+	}
+	if o.weaverCfg != nil && o.weaverCfg.BirdCfg != nil && o.cfg != nil && !reflect.DeepEqual(*o.weaverCfg.BirdCfg, *o.cfg) {
+		return errors.Wrap(ErrInvalidConfig, "the social configuration holds a different walk configuration")
+	}
+	if o.socialGraph != nil && o.weaverCfg == nil {
+		o.weaverCfg = NewWeaverCfg()
+		o.weaverCfg.BirdCfg = nil
+	}
+	if o.cfg == nil {
+		if o.weaverCfg != nil && o.weaverCfg.BirdCfg != nil {
+			o.cfg = o.weaverCfg.BirdCfg
+		} else {
+			o.cfg = NewBirdCfg()
+		}
+	}
+	if o.weaverCfg != nil {
+		o.weaverCfg.BirdCfg = o.cfg
+	}
+
+	err := validateBirdCfg(o.cfg)
+	if err != nil {
+		return err
+	}
+
+	if o.usersToTimedItems != nil {
+		o.usersToWeightedItems = DecayInteractions(o.usersToTimedItems, o.cfg.HalfLife, o.now)
+	}
+	if o.usersToWeightedItems != nil && o.cfg.HistoryLimit > 0 && o.cfg.HistoryOrder == MostRecent {
+		return errors.Wrap(ErrInvalidConfig, "weighted graphs cannot keep the most recent items of a history")
+	}
+
+	if o.rules != nil && o.attributes == nil {
+		return errors.Wrap(ErrInvalidConfig, "rules require an attribute store")
+	}
+	for _, rule := range o.rules {
+		err = validateRule(rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch o.walkMode {
+	case ItemWalk:
+		if o.weaverCfg != nil && o.weaverCfg.SocialJump > 0 {
+			return errors.Wrap(ErrInvalidConfig, "a positive SocialJump requires the social walk mode")
+		}
+	case SocialWalk:
+		if o.socialGraph == nil {
+			return errors.Wrap(ErrInvalidConfig, "social walks require a social graph")
+		}
+		if o.weaverCfg.SocialJump <= 0 {
+			return errors.Wrap(ErrInvalidConfig, "social walks require a positive SocialJump")
+		}
+	case TemporalWalk:
+		if o.usersToTimedItems == nil {
+			return errors.Wrap(ErrInvalidConfig, "temporal walks require timed interactions")
+		}
+		if o.socialGraph != nil {
+			return errors.Wrap(ErrInvalidConfig, "temporal walks cannot follow a social graph")
+		}
+	default:
+		return errors.Wrapf(ErrInvalidConfig, "unknown walk mode %d", o.walkMode)
+	}
+
+	if o.weaverCfg != nil {
+		err = validateWeaverCfg(o.weaverCfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var numUsers int
+	if o.usersToWeightedItems != nil {
+		numUsers = len(o.usersToWeightedItems)
+		if o.itemWeights == nil {
+			o.itemWeights = o.policy()(weightedItemDegrees(o.usersToWeightedItems))
+		}
+		err = validateEmuInputs(o.itemWeights, o.usersToWeightedItems)
+	} else {
+		numUsers = len(o.usersToItems)
+		if o.itemWeights == nil {
+			o.itemWeights = o.policy()(itemDegrees(o.usersToItems))
+		}
+		err = validateBirdInputs(o.itemWeights, o.usersToItems)
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.socialGraph != nil {
+		return validateSocialGraph(numUsers, o.socialGraph)
+	}
+
+	return nil
+}
+
+func (o *options) policy() ItemWeightPolicy {
+	if o.itemWeightPolicy == nil {
+		return UniformItemWeights
+	}
+
+	return o.itemWeightPolicy
+}
+
+// itemDegrees returns the number of users who interacted with each item.
+func itemDegrees(usersToItems [][]int) []int {
+	var degrees []int
+	for _, userItems := range usersToItems {
+		for _, item := range userItems {
+			if item < 0 {
+				continue
+			}
+			for item >= len(degrees) {
+				degrees = append(degrees, 0)
+			}
+			degrees[item]++
+		}
+	}
+
+	return degrees
+}
+
+// weightedItemDegrees returns the number of users who interacted with each
+// item of a weighted user-item graph.
+func weightedItemDegrees(usersToWeightedItems []map[int]float64) []int {
+	var degrees []int
+	for _, userItems := range usersToWeightedItems {
+		for item := range userItems {
+			if item < 0 {
+				continue
+			}
+			for item >= len(degrees) {
+				degrees = append(degrees, 0)
+			}
+			degrees[item]++
+		}
+	}
+
+	return degrees
+}
@@ -0,0 +1,191 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type NewCase struct {
+	Name    string
+	Options []Option
+	Weaver  bool
+	Valid   bool
+}
+
+var newTable = []NewCase{
+	{
+		Name:    "No interactions",
+		Options: []Option{WithItemWeights([]float64{1, 1})},
+		Valid:   false,
+	},
+	{
+		Name: "Weighted and unweighted interactions",
+		Options: []Option{
+			WithInteractions([][]int{{0}, {1}}),
+			WithWeightedInteractions([]map[int]float64{{0: 1}, {1: 1}}),
+		},
+		Valid: false,
+	},
+	{
+		Name: "Item weights and item weight policy",
+		Options: []Option{
+			WithInteractions([][]int{{0}, {1}}),
+			WithItemWeights([]float64{1, 1}),
+			WithItemWeightPolicy(PopularityItemWeights),
+		},
+		Valid: false,
+	},
+	{
+		Name: "Social walk without social graph",
+		Options: []Option{
+			WithInteractions([][]int{{0}, {1}}),
+			WithWalkMode(SocialWalk),
+		},
+		Valid: false,
+	},
+	{
+		Name: "Social jumps without the social walk mode",
+		Options: []Option{
+			WithInteractions([][]int{{0}, {1}}),
+			WithSocialGraph([]map[int]float64{{1: 1}, {}}, &WeaverCfg{SocialJump: 0.5}),
+		},
+		Valid: false,
+	},
+	{
+		Name: "Matching walk configurations",
+		Options: []Option{
+			WithCfg(NewBirdCfg()),
+			WithInteractions([][]int{{0}, {1}}),
+			WithSocialGraph([]map[int]float64{{1: 1}, {}}, NewWeaverCfg()),
+		},
+		Valid:  true,
+		Weaver: true,
+	},
+	{
+		Name: "Conflicting walk configurations",
+		Options: []Option{
+			WithCfg(&BirdCfg{Depth: 2, Draws: 10}),
+			WithInteractions([][]int{{0}, {1}}),
+			WithSocialGraph([]map[int]float64{{1: 1}, {}}, NewWeaverCfg()),
+		},
+		Valid: false,
+	},
+	{
+		Name: "Bird with default item weights",
+		Options: []Option{
+			WithInteractions([][]int{{0}, {1}}),
+		},
+		Weaver: false,
+		Valid:  true,
+	},
+	{
+		Name: "Emu with an item weight policy",
+		Options: []Option{
+			WithWeightedInteractions([]map[int]float64{{0: 1}, {1: 2}}),
+			WithItemWeightPolicy(InversePopularityItemWeights),
+		},
+		Weaver: false,
+		Valid:  true,
+	},
+	{
+		Name: "Weaver with social walks",
+		Options: []Option{
+			WithCfg(NewBirdCfg()),
+			WithInteractions([][]int{{0}, {1}}),
+			WithSocialGraph([]map[int]float64{{1: 1}, {}}, &WeaverCfg{DefaultWeight: 1, SocialJump: 0.5}),
+			WithWalkMode(SocialWalk),
+		},
+		Weaver: true,
+		Valid:  true,
+	},
+}
+
+func TestNew(t *testing.T) {
+	for _, ex := range newTable {
+		engine, err := New(ex.Options...)
+		if err != nil && ex.Valid {
+			t.Errorf("New: %s: should not have raised an error but did: %v", ex.Name, err)
+			continue
+		}
+		if err == nil && !ex.Valid {
+			t.Errorf("New: %s: should have raised an error but did not", ex.Name)
+			continue
+		}
+		if !ex.Valid {
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("New: %s: expected an invalid configuration error, got %v", ex.Name, err)
+			}
+			continue
+		}
+
+		_, isWeaver := engine.(*Weaver)
+		if isWeaver != ex.Weaver {
+			t.Errorf("New: %s: expected a Weaver: %v, got %T", ex.Name, ex.Weaver, engine)
+		}
+	}
+}
+
+func TestNewWeaverCfgReuse(t *testing.T) {
+	cfg := NewWeaverCfg()
+	cfg.BirdCfg = nil
+	socialGraph := []map[int]float64{{1: 1}, {}}
+
+	for _, depth := range []int{2, 3} {
+		_, err := New(WithCfg(&BirdCfg{Depth: depth, Draws: 10}), WithInteractions([][]int{{0}, {1}}), WithSocialGraph(socialGraph, cfg))
+		if err != nil {
+			t.Fatalf("New: depth %d: should not have raised an error but did: %v", depth, err)
+		}
+		if cfg.BirdCfg != nil {
+			t.Fatalf("New: depth %d: the social configuration was modified", depth)
+		}
+	}
+}
+
+func TestNewItemWeightPolicy(t *testing.T) {
+	engine, err := New(
+		WithInteractions([][]int{{0, 1}, {1}, {1, 2}}),
+		WithItemWeightPolicy(PopularityItemWeights),
+	)
+	if err != nil {
+		t.Fatalf("New: item weight policy: raised an error: %v", err)
+	}
+
+	expected := []float64{1, 3, 1}
+	weights := engine.(*Bird).ItemWeights
+	for i, w := range expected {
+		if weights[i] != w {
+			t.Errorf("New: item weight policy: expected %v, got %v", expected, weights)
+			break
+		}
+	}
+}
+
+func TestNewSeed(t *testing.T) {
+	query := []QueryItem{{Item: 0, Weight: 1}, {Item: 2, Weight: 1}}
+	usersToWeightedItems := []map[int]float64{{0: 1, 1: 2}, {1: 1, 2: 3}, {0: 2, 2: 1, 3: 1}}
+
+	var previous Walks
+	for i := 0; i < 2; i++ {
+		engine, err := New(WithWeightedInteractions(usersToWeightedItems), WithSeed(42))
+		if err != nil {
+			t.Fatalf("New: seed: raised an error: %v", err)
+		}
+		walks, _, err := engine.Walk(query, 0)
+		if err != nil {
+			t.Fatalf("New: seed: walking raised an error: %v", err)
+		}
+		if previous == nil {
+			previous = walks
+			continue
+		}
+
+		items, referrers := walks.Flatten()
+		previousItems, previousReferrers := previous.Flatten()
+		for j := range items {
+			if items[j] != previousItems[j] || referrers[j] != previousReferrers[j] {
+				t.Fatalf("New: seed: engines with the same seed performed different walks")
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package birdland
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Blending determines how the outputs of the engines of an ensemble are
+// combined.
+type Blending int
+
+const (
+	WeightedScores       Blending = iota // weighted sum of each engine's share of visits to the item
+	ReciprocalRankFusion                 // weighted sum of 1 / (RankConstant + rank of the item for each engine)
+)
+
+type EnsembleCfg struct {
+	Blending     Blending      `yaml:"blending"`
+	RankConstant float64       `yaml:"rank_constant"` // dampens the weight of the top ranks in reciprocal rank fusion
+	Timeout      time.Duration `yaml:"timeout"`       // time after which the engines that did not answer are left out, 0 waits for all of them
+}
+
+func NewEnsembleCfg() *EnsembleCfg {
+	cfg := EnsembleCfg{
+		Blending:     WeightedScores,
+		RankConstant: 60,
+		Timeout:      0,
+	}
+
+	return &cfg
+}
+
+// EnsembleMember is an engine of an ensemble along with the weight of its
+// recommendations.
+type EnsembleMember struct {
+	Engine Engine
+	Weight float64
+}
+
+// Ensemble blends the recommendations of several engines, for instance a
+// Bird built on plays and a Weaver built on follows. The query is sent to
+// all the engines concurrently, so the members must not share an engine.
+// Ensemble is safe for concurrent use: the walks of each member are
+// serialized.
+type Ensemble struct {
+	Cfg     *EnsembleCfg
+	Members []EnsembleMember
+
+	walking []sync.Mutex // held while a member walks
+	mu      sync.Mutex
+	stuck   []int // number of timed out queries each member is still answering, guarded by mu
+}
+
+// NewEnsemble returns an ensemble of the members.
+func NewEnsemble(cfg *EnsembleCfg, members ...EnsembleMember) (*Ensemble, error) {
+	err := validateEnsembleCfg(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, errors.Wrap(ErrInvalidConfig, "an ensemble needs at least one engine")
+	}
+	for i, m := range members {
+		if m.Engine == nil {
+			return nil, errors.Wrapf(ErrInvalidConfig, "member %d has no engine", i)
+		}
+		if m.Weight < 0 {
+			return nil, errors.Wrapf(ErrInvalidConfig, "the weight of member %d must be positive", i)
+		}
+	}
+
+	e := Ensemble{
+		Cfg:     cfg,
+		Members: members,
+		walking: make([]sync.Mutex, len(members)),
+		stuck:   make([]int, len(members)),
+	}
+
+	return &e, nil
+}
+
+type memberOutput struct {
+	member int
+	scored ScoredPairList
+	err    error
+}
+
+// Recommend recommends k items from the query on behalf of user by blending
+// the outputs of the members. The items of the query are not recommended,
+// and the recommended items comply with the rules of every member that is a
+// Bird or a Weaver. Concurrent calls wait for each other's walks on a member. Members that
+// return an error, that do not answer within Timeout or that are still busy
+// with a query that timed out are left out, and an error is only returned
+// when no member answered. It matches ErrTimeout or ErrBusy in the last two
+// cases.
+func (e *Ensemble) Recommend(query []QueryItem, user, k int) ([]int, error) {
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make(chan memberOutput, len(e.Members))
+	launched := make([]bool, len(e.Members))
+	finished := make([]bool, len(e.Members)) // guarded by e.mu, as timedOut
+	var timedOut bool
+	var pending int
+	var firstErr error
+	for i, m := range e.Members {
+		e.mu.Lock()
+		stuck := e.stuck[i] > 0
+		e.mu.Unlock()
+		if stuck {
+			if firstErr == nil {
+				firstErr = errors.Wrapf(ErrBusy, "member %d is still answering a query that timed out", i)
+			}
+			continue
+		}
+		launched[i] = true
+		pending++
+
+		go func(i int, m EnsembleMember) {
+			e.walking[i].Lock()
+			walks, _, err := m.Engine.Walk(query, user)
+			e.walking[i].Unlock()
+
+			e.mu.Lock()
+			finished[i] = true
+			if timedOut {
+				e.stuck[i]--
+			}
+			e.mu.Unlock()
+
+			if err != nil {
+				outputs <- memberOutput{member: i, err: err}
+				return
+			}
+			items, _ := walks.Flatten()
+			outputs <- memberOutput{member: i, scored: ScoreMostVisited(items)}
+		}(i, m)
+	}
+
+	var timeout <-chan time.Time
+	if e.Cfg.Timeout > 0 {
+		timer := time.NewTimer(e.Cfg.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	itemScores := make(map[int]float64)
+	var answered int
+collect:
+	for ; pending > 0; pending-- {
+		var out memberOutput
+		select {
+		case out = <-outputs:
+		case <-timeout:
+			// the members that are still walking are skipped by the
+			// following queries until they are done.
+			e.mu.Lock()
+			timedOut = true
+			for i := range e.Members {
+				if launched[i] && !finished[i] {
+					e.stuck[i]++
+				}
+			}
+			e.mu.Unlock()
+			if firstErr == nil {
+				firstErr = errors.Wrapf(ErrTimeout, "%d members did not answer in time", pending)
+			}
+			break collect
+		}
+
+		if out.err != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrapf(out.err, "member %d failed", out.member)
+			}
+			continue
+		}
+		answered++
+		e.blend(itemScores, e.Members[out.member].Weight, out.scored)
+	}
+
+	if answered == 0 {
+		return nil, errors.Wrap(firstErr, "no member of the ensemble answered")
+	}
+
+	excluded := make(map[int]bool, len(query))
+	for _, q := range query {
+		excluded[q.Item] = true
+	}
+
+	recommended := sortByScore(itemScores)
+	for _, m := range e.Members {
+		if rk, ok := m.Engine.(ranker); ok {
+			recommended = rk.rankItems(recommended, excluded, len(recommended))
+		}
+	}
+
+	return filterRecommendations(recommended, excluded, k), nil
+}
+
+// blend adds the normalized scores of a member to the scores of the items.
+func (e *Ensemble) blend(itemScores map[int]float64, weight float64, scored ScoredPairList) {
+	switch e.Cfg.Blending {
+	case ReciprocalRankFusion:
+		for rank, pair := range scored {
+			itemScores[pair.Object] += weight / (e.Cfg.RankConstant + float64(rank+1))
+		}
+	default:
+		var total float64
+		for _, pair := range scored {
+			total += pair.Score
+		}
+		for _, pair := range scored {
+			itemScores[pair.Object] += weight * pair.Score / total
+		}
+	}
+}
+
+// validateEnsembleCfg checks the blending parameters.
+func validateEnsembleCfg(cfg *EnsembleCfg) error {
+	if cfg.Blending != WeightedScores && cfg.Blending != ReciprocalRankFusion {
+		return errors.Wrapf(ErrInvalidConfig, "unknown blending %d", cfg.Blending)
+	}
+	if cfg.RankConstant < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the rank constant must be positive")
+	}
+	if cfg.Timeout < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the timeout must be positive")
+	}
+
+	return nil
+}
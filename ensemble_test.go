@@ -0,0 +1,240 @@
+package birdland
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stubEngine returns the same walks after a delay.
+type stubEngine struct {
+	items []int
+	delay time.Duration
+	err   error
+}
+
+func (s *stubEngine) Walk(query []QueryItem, user int) (Walks, []QueryItem, error) {
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+
+	walks := make(Walks, len(s.items))
+	for i, item := range s.items {
+		walks[i] = Walk{Origin: query[0].Item, Steps: []Step{{Referrer: 0, Item: item}}}
+	}
+
+	return walks, nil, nil
+}
+
+type EnsembleCase struct {
+	Name     string
+	Blending Blending
+	Weights  []float64
+	Expected []int
+}
+
+// The first engine mostly visits item 1, the second one item 2, and both
+// visit item 3.
+var ensemble_table = []EnsembleCase{
+	{
+		Name:     "Weighted scores, first engine",
+		Blending: WeightedScores,
+		Weights:  []float64{2, 1},
+		Expected: []int{1, 3, 2},
+	},
+	{
+		Name:     "Weighted scores, second engine",
+		Blending: WeightedScores,
+		Weights:  []float64{1, 2},
+		Expected: []int{2, 3, 1},
+	},
+	{
+		Name:     "Reciprocal rank fusion",
+		Blending: ReciprocalRankFusion,
+		Weights:  []float64{1, 1},
+		Expected: []int{3},
+	},
+}
+
+func TestEnsembleRecommend(t *testing.T) {
+	first := &stubEngine{items: []int{1, 1, 1, 1, 1, 1, 3, 3, 3, 0}}
+	second := &stubEngine{items: []int{2, 2, 2, 2, 2, 2, 3, 3}}
+
+	for _, ex := range ensemble_table {
+		cfg := NewEnsembleCfg()
+		cfg.Blending = ex.Blending
+		ensemble, err := NewEnsemble(cfg, EnsembleMember{first, ex.Weights[0]}, EnsembleMember{second, ex.Weights[1]})
+		if err != nil {
+			t.Fatalf("Ensemble: %s: initialization raised an error: %v", ex.Name, err)
+		}
+
+		recommended, err := ensemble.Recommend([]QueryItem{{0, 1}}, 0, len(ex.Expected))
+		if err != nil {
+			t.Errorf("Ensemble: %s: raised an error: %v", ex.Name, err)
+			continue
+		}
+		if len(recommended) != len(ex.Expected) {
+			t.Errorf("Ensemble: %s: expected %v, got %v", ex.Name, ex.Expected, recommended)
+			continue
+		}
+		for i := range recommended {
+			if recommended[i] != ex.Expected[i] {
+				t.Errorf("Ensemble: %s: expected %v, got %v", ex.Name, ex.Expected, recommended)
+				break
+			}
+		}
+	}
+	ensemble, err := NewEnsemble(NewEnsembleCfg(), EnsembleMember{first, 1})
+	if err != nil {
+		t.Fatalf("Ensemble: initialization raised an error: %v", err)
+	}
+	if _, err := ensemble.Recommend([]QueryItem{{0, 1}}, 0, -1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Ensemble: expected an invalid configuration error for a negative k, got %v", err)
+	}
+}
+
+func TestEnsembleDegradation(t *testing.T) {
+	fast := &stubEngine{items: []int{1, 2, 2}}
+	slow := &stubEngine{items: []int{3, 3, 3}, delay: time.Second}
+	failing := &stubEngine{err: &UnknownUserError{User: 0}}
+
+	cfg := NewEnsembleCfg()
+	cfg.Timeout = 50 * time.Millisecond
+	ensemble, err := NewEnsemble(cfg, EnsembleMember{fast, 1}, EnsembleMember{slow, 10}, EnsembleMember{failing, 1})
+	if err != nil {
+		t.Fatalf("Ensemble: initialization raised an error: %v", err)
+	}
+
+	recommended, err := ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10)
+	if err != nil {
+		t.Fatalf("Ensemble: raised an error: %v", err)
+	}
+	if len(recommended) != 2 || recommended[0] != 2 || recommended[1] != 1 {
+		t.Errorf("Ensemble: expected the recommendations of the fast engine [2 1], got %v", recommended)
+	}
+
+	// the slow engine is still busy with the previous query and is skipped
+	start := time.Now()
+	recommended, err = ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10)
+	if err != nil {
+		t.Fatalf("Ensemble: raised an error: %v", err)
+	}
+	if len(recommended) != 2 || time.Since(start) >= cfg.Timeout {
+		t.Errorf("Ensemble: expected the busy engine to be skipped, got %v after %v", recommended, time.Since(start))
+	}
+
+	// a member that times out is skipped until it is done
+	slowCfg := NewEnsembleCfg()
+	slowCfg.Timeout = 50 * time.Millisecond
+	ensemble, err = NewEnsemble(slowCfg, EnsembleMember{&stubEngine{items: []int{3}, delay: 200 * time.Millisecond}, 1})
+	if err != nil {
+		t.Fatalf("Ensemble: initialization raised an error: %v", err)
+	}
+	if _, err := ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Ensemble: expected a timeout error, got %v", err)
+	}
+	if _, err := ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10); !errors.Is(err, ErrBusy) {
+		t.Errorf("Ensemble: expected a busy error, got %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	slowCfg.Timeout = 0
+	if _, err := ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10); err != nil {
+		t.Errorf("Ensemble: the member should answer once done with the query that timed out, got %v", err)
+	}
+
+	ensemble, err = NewEnsemble(cfg, EnsembleMember{failing, 1})
+	if err != nil {
+		t.Fatalf("Ensemble: initialization raised an error: %v", err)
+	}
+	if _, err := ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("Ensemble: expected the error of the only member, got %v", err)
+	}
+}
+
+func TestEnsembleConcurrent(t *testing.T) {
+	first := &stubEngine{items: []int{1, 1, 3}, delay: 10 * time.Millisecond}
+	second := &stubEngine{items: []int{2, 2, 3}, delay: 10 * time.Millisecond}
+	ensemble, err := NewEnsemble(NewEnsembleCfg(), EnsembleMember{first, 2}, EnsembleMember{second, 1})
+	if err != nil {
+		t.Fatalf("Ensemble: initialization raised an error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	results := make([][]int, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Ensemble: concurrent call %d raised an error: %v", i, err)
+			continue
+		}
+		if len(results[i]) != 3 || results[i][0] != 1 || results[i][1] != 3 || results[i][2] != 2 {
+			t.Errorf("Ensemble: concurrent call %d: expected [1 3 2], got %v", i, results[i])
+		}
+	}
+}
+
+func TestEnsembleWithEngines(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.Draws = 100
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1, 1, 1}, similarUsersToItems)
+	if err != nil {
+		t.Fatalf("Ensemble: Bird initialization raised an error: %v", err)
+	}
+	weaverCfg := NewWeaverCfg()
+	weaverCfg.Draws = 100
+	weaver, err := NewWeaver(weaverCfg, []float64{1, 1, 1, 1, 1, 1}, similarUsersToItems, []map[int]float64{{1: 1}, {}, {}, {}, {}})
+	if err != nil {
+		t.Fatalf("Ensemble: Weaver initialization raised an error: %v", err)
+	}
+
+	ensemble, err := NewEnsemble(NewEnsembleCfg(), EnsembleMember{bird, 1}, EnsembleMember{weaver, 1})
+	if err != nil {
+		t.Fatalf("Ensemble: initialization raised an error: %v", err)
+	}
+	recommended, err := ensemble.Recommend([]QueryItem{{3, 1}}, 0, 10)
+	if err != nil {
+		t.Fatalf("Ensemble: raised an error: %v", err)
+	}
+	if len(recommended) != 1 || recommended[0] != 4 {
+		t.Errorf("Ensemble: expected item 4, got %v", recommended)
+	}
+
+	// the rules of the members apply to the blended recommendations
+	store := NewAttributeStore()
+	store.Set(1, "explicit", "true")
+	rules, err := ParseRules("exclude explicit=true")
+	if err != nil {
+		t.Fatalf("Ensemble: parsing the rules raised an error: %v", err)
+	}
+	engine, err := New(WithCfg(cfg), WithInteractions(similarUsersToItems), WithAttributes(store, rules...))
+	if err != nil {
+		t.Fatalf("Ensemble: Bird initialization raised an error: %v", err)
+	}
+	ensemble, err = NewEnsemble(NewEnsembleCfg(), EnsembleMember{engine, 1}, EnsembleMember{weaver, 1})
+	if err != nil {
+		t.Fatalf("Ensemble: initialization raised an error: %v", err)
+	}
+	recommended, err = ensemble.Recommend([]QueryItem{{0, 1}}, 0, 10)
+	if err != nil {
+		t.Fatalf("Ensemble: raised an error: %v", err)
+	}
+	if len(recommended) != 1 || recommended[0] != 2 {
+		t.Errorf("Ensemble: expected the explicit item 1 to be excluded, got %v", recommended)
+	}
+
+	if _, err := NewEnsemble(NewEnsembleCfg()); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Ensemble: an empty ensemble should raise an invalid configuration error, got %v", err)
+	}
+}
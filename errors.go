@@ -0,0 +1,59 @@
+package birdland
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Errors returned by the engines. They are wrapped with context as they
+// travel up the call stack and can be identified with errors.Is, so callers
+// can tell a bad request (empty query, unknown item or user) from a failure
+// of the engine.
+var (
+	ErrEmptyQuery    = errors.New("empty query")
+	ErrUnknownItem   = errors.New("unknown item")
+	ErrUnknownUser   = errors.New("unknown user")
+	ErrDeadEnd       = errors.New("dead end")
+	ErrInvalidConfig = errors.New("invalid configuration")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrTimeout       = errors.New("timeout")
+	ErrBusy          = errors.New("busy")
+)
+
+// UnknownItemError is returned when a query refers to an item that is not
+// part of the graph, i.e. one that has no weight in ItemWeights. It matches
+// ErrUnknownItem.
+type UnknownItemError struct {
+	Item int
+}
+
+func (e *UnknownItemError) Error() string {
+	return fmt.Sprintf("item %d does not belong to the graph", e.Item)
+}
+
+func (e *UnknownItemError) Is(target error) bool { return target == ErrUnknownItem }
+
+// UnknownUserError is returned when a request is made on behalf of a user
+// that is not part of the graph. It matches ErrUnknownUser.
+type UnknownUserError struct {
+	User int
+}
+
+func (e *UnknownUserError) Error() string {
+	return fmt.Sprintf("user %d does not belong to the social graph", e.User)
+}
+
+func (e *UnknownUserError) Is(target error) bool { return target == ErrUnknownUser }
+
+// DeadEndError is returned when a random walk reaches an item no one has
+// interacted with and thus cannot go any further. It matches ErrDeadEnd.
+type DeadEndError struct {
+	Item int
+}
+
+func (e *DeadEndError) Error() string {
+	return fmt.Sprintf("no one has interacted with item %d", e.Item)
+}
+
+func (e *DeadEndError) Is(target error) bool { return target == ErrDeadEnd }
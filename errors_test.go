@@ -0,0 +1,124 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rlouf/birdland/sampler"
+)
+
+type ErrorCase struct {
+	Name     string
+	Run      func() error
+	Expected error
+}
+
+var errorTable = []ErrorCase{
+	{
+		Name: "Bird with zero depth",
+		Run: func() error {
+			cfg := NewBirdCfg()
+			cfg.Depth = 0
+			_, err := NewBird(cfg, []float64{1}, [][]int{{0}})
+			return err
+		},
+		Expected: ErrInvalidConfig,
+	},
+	{
+		Name: "Emu with zero draws",
+		Run: func() error {
+			cfg := NewBirdCfg()
+			cfg.Draws = 0
+			_, err := NewEmu(cfg, []float64{1}, []map[int]float64{{0: 1}})
+			return err
+		},
+		Expected: ErrInvalidConfig,
+	},
+	{
+		Name: "Emu with negative interaction weight",
+		Run: func() error {
+			_, err := NewEmu(NewBirdCfg(), []float64{1}, []map[int]float64{{0: -1}})
+			return err
+		},
+		Expected: ErrInvalidInput,
+	},
+	{
+		Name: "Weaver with mismatched social graph",
+		Run: func() error {
+			_, err := NewWeaver(NewWeaverCfg(), []float64{1}, [][]int{{0}}, []map[int]float64{{}, {}})
+			return err
+		},
+		Expected: ErrInvalidInput,
+	},
+	{
+		Name: "Bird with empty query",
+		Run: func() error {
+			bird, _ := NewBird(NewBirdCfg(), []float64{1}, [][]int{{0}})
+			_, _, err := bird.Process([]QueryItem{})
+			return err
+		},
+		Expected: ErrEmptyQuery,
+	},
+	{
+		Name: "Bird with a query of isolated items",
+		Run: func() error {
+			bird, _ := NewBird(NewBirdCfg(), []float64{1, 1}, [][]int{{0}})
+			_, _, err := bird.Process([]QueryItem{{Item: 1, Weight: 1}})
+			return err
+		},
+		Expected: ErrEmptyQuery,
+	},
+	{
+		Name: "Bird with unknown item",
+		Run: func() error {
+			bird, _ := NewBird(NewBirdCfg(), []float64{1}, [][]int{{0}})
+			_, _, err := bird.Process([]QueryItem{{Item: 3, Weight: 1}})
+			return err
+		},
+		Expected: ErrUnknownItem,
+	},
+	{
+		Name: "Weaver with empty query",
+		Run: func() error {
+			weaver, _ := NewWeaver(NewWeaverCfg(), []float64{1}, [][]int{{0}}, []map[int]float64{{}})
+			_, _, err := weaver.Process([]QueryItem{}, 0)
+			return err
+		},
+		Expected: ErrEmptyQuery,
+	},
+	{
+		Name: "Weaver with unknown user",
+		Run: func() error {
+			weaver, _ := NewWeaver(NewWeaverCfg(), []float64{1}, [][]int{{0}}, []map[int]float64{{}})
+			_, _, err := weaver.Process([]QueryItem{{Item: 0, Weight: 1}}, 1)
+			return err
+		},
+		Expected: ErrUnknownUser,
+	},
+	{
+		Name: "Bird stepping from an isolated item",
+		Run: func() error {
+			bird, _ := NewBird(NewBirdCfg(), []float64{1, 1}, [][]int{{0}})
+			_, _, err := bird.step([]int{1})
+			return err
+		},
+		Expected: ErrDeadEnd,
+	},
+	{
+		Name: "Bird with null item weights",
+		Run: func() error {
+			_, err := NewBird(NewBirdCfg(), []float64{0}, [][]int{{0}})
+			return err
+		},
+		Expected: sampler.ErrNullWeights,
+	},
+}
+
+func TestErrors(t *testing.T) {
+	for _, ex := range errorTable {
+		err := ex.Run()
+		if !errors.Is(err, ex.Expected) {
+			t.Errorf("Errors: %s: expected error matching %q, got %v", ex.Name, ex.Expected, err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package birdland
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Source tells how the items of a Recommendation were obtained.
+type Source int
+
+const (
+	FromWalks      Source = iota // random walks from the query, the usual way
+	FromPopular                  // the most popular items, by ItemWeights
+	FromAttributes               // the most popular items that share an attribute value with the query items
+	FromItemWalks                // random walks that ignore the social graph, for users Weaver does not know
+	FromFriends                  // the items of the user's connections in the social graph
+)
+
+// Recommendation is a list of recommended items along with the way they were
+// obtained.
+type Recommendation struct {
+	Items  []int
+	Source Source
+}
+
+// Recommend recommends k items from the query. When the walks cannot be
+// performed, because no one has interacted with the items of the query or
+// they are not part of the graph, or when they recommend nothing, the
+// fallbacks of the configuration are tried in order until one of them
+// recommends items. The items of the query are never recommended, and the
+// engine's rules apply.
+func (b *Bird) Recommend(query []QueryItem, k int) (*Recommendation, error) {
+	return b.recommend(b, nil, query, 0, k)
+}
+
+// Recommend recommends k items from the query on behalf of user. On top of
+// those of Bird, Weaver supports the FromItemWalks fallback for users outside
+// the social graph, and the FromFriends fallback.
+func (b *Weaver) Recommend(query []QueryItem, user, k int) (*Recommendation, error) {
+	return b.Bird.recommend(b, b, query, user, k)
+}
+
+func (b *Bird) recommend(e Engine, weaver *Weaver, query []QueryItem, user, k int) (*Recommendation, error) {
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[int]bool, len(query))
+	for _, q := range query {
+		excluded[q.Item] = true
+	}
+
+	// walks that only visit excluded items, or that stop before their first
+	// step, recommend nothing and the fallbacks are tried as well.
+	var fromWalks []int
+	walks, _, err := e.Walk(query, user)
+	if err == nil {
+		items, referrers := walks.Flatten()
+		fromWalks = b.rankItems(RecommendItems(items, referrers), excluded, k)
+		if len(fromWalks) > 0 {
+			return &Recommendation{fromWalks, FromWalks}, nil
+		}
+	} else if !coldStart(err) {
+		return nil, err
+	}
+
+	for _, fallback := range b.Cfg.Fallbacks {
+		var recommended []int
+		switch fallback {
+		case FromPopular:
+			recommended = b.popularItems(func(item int) bool { return true })
+		case FromAttributes:
+			recommended = b.attributeNeighbourhood(query)
+		case FromItemWalks:
+			if weaver == nil {
+				continue
+			}
+			walks, _, walkErr := b.ProcessWalks(query)
+			if walkErr != nil {
+				continue
+			}
+			items, referrers := walks.Flatten()
+			recommended = RecommendItems(items, referrers)
+		case FromFriends:
+			if weaver == nil || user < 0 || user >= len(weaver.SocialGraph) {
+				continue
+			}
+			recommended = weaver.friendsItems(user)
+		}
+
+		recommended = b.rankItems(recommended, excluded, k)
+		if len(recommended) > 0 {
+			return &Recommendation{recommended, fallback}, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recommendation{fromWalks, FromWalks}, nil
+}
+
+// coldStart reports whether the error is due to a query or a user the graph
+// knows nothing about.
+func coldStart(err error) bool {
+	return errors.Is(err, ErrEmptyQuery) || errors.Is(err, ErrUnknownItem) ||
+		errors.Is(err, ErrUnknownUser) || errors.Is(err, ErrDeadEnd)
+}
+
+// popularItems returns the items someone has interacted with that satisfy
+// keep, in descending order of weight and then of number of users.
+func (b *Bird) popularItems(keep func(item int) bool) []int {
+	var items []int
+	for item, users := range b.ItemsToUsers {
+		if len(users) > 0 && b.ItemWeights[item] > 0 && keep(item) {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, c := items[i], items[j]
+		if b.ItemWeights[a] != b.ItemWeights[c] {
+			return b.ItemWeights[a] > b.ItemWeights[c]
+		}
+		return len(b.ItemsToUsers[a]) > len(b.ItemsToUsers[c])
+	})
+
+	return items
+}
+
+// attributeNeighbourhood returns the popular items that share the value of
+// an attribute with one of the query items. Query items do not need to be
+// part of the graph, which makes it possible to recommend from new items.
+func (b *Bird) attributeNeighbourhood(query []QueryItem) []int {
+	if b.Attributes == nil {
+		return nil
+	}
+
+	b.Attributes.mu.RLock()
+	neighbourhood := make(map[string]map[string]bool)
+	for _, q := range query {
+		for attribute, values := range b.Attributes.attributes[q.Item] {
+			if _, ok := neighbourhood[attribute]; !ok {
+				neighbourhood[attribute] = make(map[string]bool)
+			}
+			for _, v := range values {
+				neighbourhood[attribute][v] = true
+			}
+		}
+	}
+	b.Attributes.mu.RUnlock()
+	if len(neighbourhood) == 0 {
+		return nil
+	}
+
+	return b.popularItems(func(item int) bool {
+		for attribute, values := range neighbourhood {
+			for _, v := range b.Attributes.Get(item, attribute) {
+				if values[v] {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// friendsItems returns the items of the user's connections, in descending
+// order of the cumulated weight of the connections who interacted with them.
+func (b *Weaver) friendsItems(user int) []int {
+	itemScores := make(map[int]float64)
+	for friend, w := range b.connections(user) {
+		for _, item := range b.UsersToItems[friend] {
+			itemScores[item] += w
+		}
+	}
+
+	return positiveScores(itemScores)
+}
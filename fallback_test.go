@@ -0,0 +1,218 @@
+package birdland
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type FallbackCase struct {
+	Name      string
+	Fallbacks []Source
+	Query     []QueryItem
+	User      int
+	Source    Source
+	Expected  []int
+	Valid     bool
+}
+
+// Items 0, 1 and 2 are rock, items 3 and 4 are jazz, and no one has
+// listened to the new jazz item 5 yet.
+var fallbackItemWeights = []float64{1, 2, 3, 5, 4, 1}
+
+var fallbackBirdTable = []FallbackCase{
+	{
+		Name:      "Walks",
+		Fallbacks: []Source{FromPopular},
+		Query:     []QueryItem{{3, 1}},
+		Source:    FromWalks,
+		Expected:  []int{4},
+		Valid:     true,
+	},
+	{
+		Name:      "Walks that only visit the query",
+		Fallbacks: []Source{FromPopular},
+		Query:     []QueryItem{{3, 1}, {4, 1}},
+		Source:    FromPopular,
+		Expected:  []int{2, 1, 0},
+		Valid:     true,
+	},
+	{
+		Name:      "Walks that only visit the query without fallback",
+		Fallbacks: nil,
+		Query:     []QueryItem{{3, 1}, {4, 1}},
+		Source:    FromWalks,
+		Expected:  []int{},
+		Valid:     true,
+	},
+	{
+		Name:      "No fallback",
+		Fallbacks: nil,
+		Query:     []QueryItem{{5, 1}},
+		Valid:     false,
+	},
+	{
+		Name:      "Popular items",
+		Fallbacks: []Source{FromPopular},
+		Query:     []QueryItem{{5, 1}},
+		Source:    FromPopular,
+		Expected:  []int{3, 4, 2},
+		Valid:     true,
+	},
+	{
+		Name:      "Attribute neighbourhood of a new item",
+		Fallbacks: []Source{FromAttributes, FromPopular},
+		Query:     []QueryItem{{5, 1}},
+		Source:    FromAttributes,
+		Expected:  []int{3, 4},
+		Valid:     true,
+	},
+	{
+		Name:      "Items that are not part of the graph",
+		Fallbacks: []Source{FromAttributes, FromPopular},
+		Query:     []QueryItem{{6, 1}},
+		Source:    FromPopular,
+		Expected:  []int{3, 4, 2},
+		Valid:     true,
+	},
+	{
+		Name:      "Social fallbacks do not apply to Bird",
+		Fallbacks: []Source{FromItemWalks, FromFriends},
+		Query:     []QueryItem{{5, 1}},
+		Valid:     false,
+	},
+}
+
+var fallbackWeaverTable = []FallbackCase{
+	{
+		Name:      "Unknown user",
+		Fallbacks: []Source{FromItemWalks, FromPopular},
+		Query:     []QueryItem{{3, 1}},
+		User:      5,
+		Source:    FromItemWalks,
+		Expected:  []int{4},
+		Valid:     true,
+	},
+	{
+		Name:      "Friends' items",
+		Fallbacks: []Source{FromFriends, FromPopular},
+		Query:     []QueryItem{{5, 1}},
+		User:      0,
+		Source:    FromFriends,
+		Expected:  []int{2, 1, 0},
+		Valid:     true,
+	},
+	{
+		Name:      "Unknown user without friends",
+		Fallbacks: []Source{FromFriends, FromPopular},
+		Query:     []QueryItem{{5, 1}},
+		User:      5,
+		Source:    FromPopular,
+		Expected:  []int{3, 4, 2},
+		Valid:     true,
+	},
+}
+
+func newFallbackStore() *AttributeStore {
+	store := NewAttributeStore()
+	for _, item := range []int{0, 1, 2} {
+		store.Set(item, "genre", "rock")
+	}
+	for _, item := range []int{3, 4, 5} {
+		store.Set(item, "genre", "jazz")
+	}
+
+	return store
+}
+
+func checkFallback(t *testing.T, ex FallbackCase, recommendation *Recommendation, err error) {
+	if !ex.Valid {
+		if err == nil {
+			t.Errorf("Recommend: %s: expected an error, got %v", ex.Name, recommendation)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("Recommend: %s: raised an error: %v", ex.Name, err)
+		return
+	}
+	if recommendation.Source != ex.Source {
+		t.Errorf("Recommend: %s: expected source %d, got %d", ex.Name, ex.Source, recommendation.Source)
+	}
+	if len(recommendation.Items) != len(ex.Expected) {
+		t.Errorf("Recommend: %s: expected %v, got %v", ex.Name, ex.Expected, recommendation.Items)
+		return
+	}
+	for i := range ex.Expected {
+		if recommendation.Items[i] != ex.Expected[i] {
+			t.Errorf("Recommend: %s: expected %v, got %v", ex.Name, ex.Expected, recommendation.Items)
+			break
+		}
+	}
+}
+
+func TestBirdRecommend(t *testing.T) {
+	for _, ex := range fallbackBirdTable {
+		cfg := NewBirdCfg()
+		cfg.Draws = 100
+		cfg.Fallbacks = ex.Fallbacks
+		engine, err := New(WithCfg(cfg), WithInteractions(similarUsersToItems),
+			WithItemWeights(fallbackItemWeights), WithAttributes(newFallbackStore()))
+		if err != nil {
+			t.Fatalf("Recommend: %s: initialization raised an error: %v", ex.Name, err)
+		}
+
+		recommendation, err := engine.(*Bird).Recommend(ex.Query, 3)
+		checkFallback(t, ex, recommendation, err)
+	}
+
+	bird, err := NewBird(NewBirdCfg(), fallbackItemWeights, similarUsersToItems)
+	if err != nil {
+		t.Fatalf("Recommend: Bird initialization raised an error: %v", err)
+	}
+	if _, err := bird.Recommend([]QueryItem{{3, 1}}, -1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Recommend: expected an invalid configuration error for a negative k, got %v", err)
+	}
+}
+
+func TestWeaverRecommend(t *testing.T) {
+	// user 0 follows users 1 and 2
+	socialGraph := []map[int]float64{{1: 3, 2: 1}, {}, {}, {}, {}}
+	for _, ex := range fallbackWeaverTable {
+		cfg := NewWeaverCfg()
+		cfg.Draws = 100
+		cfg.Fallbacks = ex.Fallbacks
+		engine, err := New(WithCfg(cfg.BirdCfg), WithInteractions(similarUsersToItems),
+			WithItemWeights(fallbackItemWeights), WithSocialGraph(socialGraph, cfg))
+		if err != nil {
+			t.Fatalf("Recommend: %s: initialization raised an error: %v", ex.Name, err)
+		}
+
+		recommendation, err := engine.(*Weaver).Recommend(ex.Query, ex.User, 3)
+		checkFallback(t, ex, recommendation, err)
+	}
+}
+
+func TestTemporalRecommend(t *testing.T) {
+	bird := newTemporalBird(t, 90*time.Second)
+	bird.Cfg.Fallbacks = []Source{FromPopular}
+
+	// no one listened to anything after item 2
+	recommendation, err := bird.Recommend([]QueryItem{{2, 1}}, 3)
+	if err != nil {
+		t.Fatalf("Recommend: temporal walks: raised an error: %v", err)
+	}
+	if recommendation.Source != FromPopular || len(recommendation.Items) == 0 {
+		t.Errorf("Recommend: temporal walks: expected popular items, got %v from source %d",
+			recommendation.Items, recommendation.Source)
+	}
+}
+
+func TestFallbackCfg(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.Fallbacks = []Source{FromWalks}
+	if _, err := NewBird(cfg, []float64{1}, [][]int{{0}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Recommend: walks are not a fallback and should raise an invalid configuration error, got %v", err)
+	}
+}
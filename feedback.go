@@ -0,0 +1,128 @@
+package birdland
+
+import "github.com/pkg/errors"
+
+// WalkFeedback performs random walks on behalf of user from the items of the
+// query, which the user liked, and separate walks from the items of the
+// negative query, which the user disliked or skipped. The weights of the
+// negative query items measure how strongly they were disliked and must be
+// positive. Negative query items that are not part of the graph, such as
+// items added since the engine was built, are dropped. There are no negative
+// walks if the negative query is empty or only contains items no one has
+// interacted with or that are not part of the graph.
+func WalkFeedback(e Engine, query, negative []QueryItem, user int) (Walks, Walks, error) {
+	positiveWalks, _, err := e.Walk(query, user)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot walk from the query")
+	}
+
+	var negativeWalks Walks
+	for {
+		if len(negative) == 0 {
+			return positiveWalks, nil, nil
+		}
+		negativeWalks, _, err = e.Walk(negative, user)
+		var unknown *UnknownItemError
+		if !errors.As(err, &unknown) {
+			break
+		}
+		kept := dropItem(negative, unknown.Item)
+		if len(kept) == len(negative) {
+			break
+		}
+		negative = kept
+	}
+	if errors.Is(err, ErrEmptyQuery) {
+		return positiveWalks, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot walk from the negative query")
+	}
+
+	return positiveWalks, negativeWalks, nil
+}
+
+// dropItem returns a copy of the query without the item.
+func dropItem(query []QueryItem, item int) []QueryItem {
+	kept := make([]QueryItem, 0, len(query))
+	for _, q := range query {
+		if q.Item != item {
+			kept = append(kept, q)
+		}
+	}
+
+	return kept
+}
+
+// RecommendFeedback recommends the items in descending order of their share
+// of the visits of the positive walks minus penalty times their share of the
+// visits of the negative walks. Items that are visited proportionally more
+// from the disliked items than from the liked ones, which have a score lower
+// than or equal to zero, are not recommended.
+func RecommendFeedback(positive, negative Walks, penalty float64) []int {
+	itemScores := visitShares(positive, func(step Step) int { return step.Item })
+	for item, share := range visitShares(negative, func(step Step) int { return step.Item }) {
+		if _, ok := itemScores[item]; ok {
+			itemScores[item] -= penalty * share
+		}
+	}
+
+	return positiveScores(itemScores)
+}
+
+// RecommendTrustFeedback is the negative feedback counterpart of
+// RecommendTrust. Referrers are trusted in proportion to their share of the
+// traversals of the positive walks minus penalty times their share of the
+// traversals of the negative walks, so that users who are reached mainly
+// through disliked items get less trust, or none. Items are recommended by
+// descending order of the cumulated trust of their referrers in the positive
+// walks.
+func RecommendTrustFeedback(positive, negative Walks, penalty float64) []int {
+	referrerTrust := visitShares(positive, func(step Step) int { return step.Referrer })
+	for referrer, share := range visitShares(negative, func(step Step) int { return step.Referrer }) {
+		if _, ok := referrerTrust[referrer]; ok {
+			referrerTrust[referrer] -= penalty * share
+		}
+	}
+
+	itemScores := make(map[int]float64)
+	for _, walk := range positive {
+		for _, step := range walk.Steps {
+			if trust := referrerTrust[step.Referrer]; trust > 0 {
+				itemScores[step.Item] += trust
+			}
+		}
+	}
+
+	return positiveScores(itemScores)
+}
+
+// visitShares returns the share of the steps of the walks that visited each
+// object, as returned by key.
+func visitShares(walks Walks, key func(step Step) int) map[int]float64 {
+	counts := make(map[int]float64)
+	var total float64
+	for _, walk := range walks {
+		for _, step := range walk.Steps {
+			counts[key(step)]++
+			total++
+		}
+	}
+	for object := range counts {
+		counts[object] /= total
+	}
+
+	return counts
+}
+
+// positiveScores returns the objects with a positive score by descending
+// order of score.
+func positiveScores(scores map[int]float64) []int {
+	for object, score := range scores {
+		if score <= 0 {
+			delete(scores, object)
+		}
+	}
+
+	return sortByScore(scores)
+}
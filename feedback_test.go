@@ -0,0 +1,133 @@
+package birdland
+
+import "testing"
+
+var feedbackPositiveWalks = Walks{
+	{Origin: 0, Steps: []Step{{10, 1}, {10, 2}}},
+	{Origin: 0, Steps: []Step{{11, 3}, {12, 1}}},
+	{Origin: 0, Steps: []Step{{12, 1}}},
+}
+
+var feedbackNegativeWalks = Walks{
+	{Origin: 5, Steps: []Step{{10, 2}, {10, 2}}},
+}
+
+type FeedbackCase struct {
+	Name     string
+	Negative Walks
+	Penalty  float64
+	Expected []int
+}
+
+var feedback_table = []FeedbackCase{
+	{
+		Name:     "Light penalty",
+		Negative: feedbackNegativeWalks,
+		Penalty:  0.1,
+		Expected: []int{1, 3, 2},
+	},
+	{
+		Name:     "Strong penalty",
+		Negative: feedbackNegativeWalks,
+		Penalty:  1,
+		Expected: []int{1, 3},
+	},
+}
+
+var trustFeedback_table = []FeedbackCase{
+	{
+		Name:     "No negative walks",
+		Negative: nil,
+		Penalty:  1,
+		Expected: []int{1, 2, 3},
+	},
+	{
+		Name:     "Referrer reached through disliked items",
+		Negative: feedbackNegativeWalks,
+		Penalty:  1,
+		Expected: []int{1, 3},
+	},
+}
+
+func TestRecommendFeedback(t *testing.T) {
+	for _, ex := range feedback_table {
+		recommended := RecommendFeedback(feedbackPositiveWalks, ex.Negative, ex.Penalty)
+		if len(recommended) != len(ex.Expected) {
+			t.Errorf("RecommendFeedback: %s: expected %v, got %v", ex.Name, ex.Expected, recommended)
+			continue
+		}
+		for i, r := range recommended {
+			if r != ex.Expected[i] {
+				t.Errorf("RecommendFeedback: %s: expected %v, got %v", ex.Name, ex.Expected, recommended)
+				break
+			}
+		}
+	}
+}
+
+func TestRecommendTrustFeedback(t *testing.T) {
+	for _, ex := range trustFeedback_table {
+		recommended := RecommendTrustFeedback(feedbackPositiveWalks, ex.Negative, ex.Penalty)
+		if len(recommended) != len(ex.Expected) {
+			t.Errorf("RecommendTrustFeedback: %s: expected %v, got %v", ex.Name, ex.Expected, recommended)
+			continue
+		}
+		for i, r := range recommended {
+			if r != ex.Expected[i] {
+				t.Errorf("RecommendTrustFeedback: %s: expected %v, got %v", ex.Name, ex.Expected, recommended)
+				break
+			}
+		}
+	}
+}
+
+func TestWalkFeedback(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.Draws = 10
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1, 1, 1}, similarUsersToItems)
+	if err != nil {
+		t.Fatalf("WalkFeedback: Bird initialization raised an error: %v", err)
+	}
+
+	positive, negative, err := WalkFeedback(bird, []QueryItem{{0, 1}}, []QueryItem{{3, 1}}, 0)
+	if err != nil {
+		t.Fatalf("WalkFeedback: raised an error: %v", err)
+	}
+	if len(positive) != 10 || len(negative) != 10 {
+		t.Errorf("WalkFeedback: expected 10 positive and 10 negative walks, got %d and %d", len(positive), len(negative))
+	}
+	for _, w := range negative {
+		if w.Origin != 3 {
+			t.Errorf("WalkFeedback: negative walks should start from the negative query, got origin %d", w.Origin)
+		}
+	}
+
+	// no one has interacted with item 5
+	_, negative, err = WalkFeedback(bird, []QueryItem{{0, 1}}, []QueryItem{{5, 1}}, 0)
+	if err != nil {
+		t.Fatalf("WalkFeedback: raised an error: %v", err)
+	}
+	if negative != nil {
+		t.Errorf("WalkFeedback: expected no negative walks, got %v", negative)
+	}
+
+	// item 6 is not part of the graph
+	_, negative, err = WalkFeedback(bird, []QueryItem{{0, 1}}, []QueryItem{{6, 1}, {3, 1}}, 0)
+	if err != nil {
+		t.Fatalf("WalkFeedback: raised an error: %v", err)
+	}
+	if len(negative) != 10 || negative[0].Origin != 3 {
+		t.Errorf("WalkFeedback: expected negative walks from item 3 only, got %v", negative)
+	}
+	_, negative, err = WalkFeedback(bird, []QueryItem{{0, 1}}, []QueryItem{{6, 1}}, 0)
+	if err != nil {
+		t.Fatalf("WalkFeedback: raised an error: %v", err)
+	}
+	if negative != nil {
+		t.Errorf("WalkFeedback: expected no negative walks, got %v", negative)
+	}
+
+	if _, _, err := WalkFeedback(bird, nil, []QueryItem{{3, 1}}, 0); err == nil {
+		t.Errorf("WalkFeedback: an empty query should have raised an error")
+	}
+}
@@ -0,0 +1,190 @@
+package birdland
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// GroupMember is a user taking part in a group recommendation. Weight sets
+// how much the member's tastes count relative to the other members'.
+type GroupMember struct {
+	User   int
+	Weight float64
+}
+
+// GroupAggregation determines how the satisfaction of each member of a group
+// is combined into the score of an item.
+type GroupAggregation int
+
+const (
+	Average      GroupAggregation = iota // weighted mean of the members' satisfaction
+	LeastMisery                          // satisfaction of the least satisfied member
+	MostPleasure                         // satisfaction of the most satisfied member
+)
+
+// RecommendForGroup recommends k items to a group of users, such as the
+// guests of a party or the members of a shared account. The walks start from
+// a query that combines the members' histories, each member contributing in
+// proportion to their weight, and every walk is credited to the members
+// according to their share of its origin. Items are ranked by aggregating the
+// members' satisfaction, i.e. the share of their walks that visited the item.
+// The items any member already interacted with are excluded.
+func (b *Bird) RecommendForGroup(members []GroupMember, aggregation GroupAggregation, k int) ([]int, error) {
+	return recommendForGroup(b, members, aggregation, k, func(query []QueryItem) (Walks, error) {
+		walks, _, err := b.ProcessWalks(query)
+		return walks, err
+	})
+}
+
+// RecommendForGroup recommends k items to a group of users as Bird does, the
+// walks being weighted by the union of the members' social graphs. The
+// connections of each member are weighted by the member's weight.
+func (b *Weaver) RecommendForGroup(members []GroupMember, aggregation GroupAggregation, k int) ([]int, error) {
+	return recommendForGroup(b.Bird, members, aggregation, k, func(query []QueryItem) (Walks, error) {
+		connections := b.groupConnections(members)
+		walks, _, err := b.processWalks(query, func(items []int) ([]int, []int, error) {
+			return b.socialStep(items, func(item int) (indexSampler, error) {
+				s, err := newTwoStageSampler(b.RandSource, b.ItemsToUsers[item], connections, b.Cfg.DefaultWeight)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not initialize users' sampler for the group and item %d", item)
+				}
+				return s, nil
+			})
+		})
+		return walks, err
+	})
+}
+
+// groupConnections merges the connections of the members, weighted by the
+// members' normalized weights. Members must have been validated.
+func (b *Weaver) groupConnections(members []GroupMember) map[int]float64 {
+	var totalWeight float64
+	for _, m := range members {
+		totalWeight += m.Weight
+	}
+
+	connections := make(map[int]float64)
+	for _, m := range members {
+		for u, w := range b.connections(m.User) {
+			connections[u] += w * m.Weight / totalWeight
+		}
+	}
+
+	return connections
+}
+
+func recommendForGroup(b *Bird, members []GroupMember, aggregation GroupAggregation, k int,
+	walk func(query []QueryItem) (Walks, error)) ([]int, error) {
+
+	if len(members) == 0 {
+		return nil, errors.Wrap(ErrEmptyQuery, "the group has no members")
+	}
+	if aggregation < Average || aggregation > MostPleasure {
+		return nil, errors.Wrapf(ErrInvalidConfig, "unknown group aggregation %d", aggregation)
+	}
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+
+	// shares[i][m] is the contribution of member m to the weight of item i in
+	// the combined query.
+	shares := make(map[int][]float64)
+	owned := make(map[int]bool)
+	for m, member := range members {
+		if member.Weight <= 0 {
+			return nil, errors.Wrapf(ErrInvalidInput, "the weight of member %d must be strictly positive", member.User)
+		}
+		history, err := b.UserQuery(member.User)
+		if err != nil {
+			return nil, err
+		}
+
+		var historyWeight float64
+		for _, q := range history {
+			historyWeight += q.Weight
+		}
+		for _, q := range history {
+			owned[q.Item] = true
+			if historyWeight == 0 {
+				continue
+			}
+			if _, ok := shares[q.Item]; !ok {
+				shares[q.Item] = make([]float64, len(members))
+			}
+			shares[q.Item][m] += member.Weight * q.Weight / historyWeight
+		}
+	}
+
+	query := make([]QueryItem, 0, len(shares))
+	for item, itemShares := range shares {
+		var weight float64
+		for _, share := range itemShares {
+			weight += share
+		}
+		for m := range itemShares {
+			itemShares[m] /= weight
+		}
+		query = append(query, QueryItem{Item: item, Weight: weight})
+	}
+	sort.Slice(query, func(i, j int) bool { return query[i].Item < query[j].Item })
+
+	walks, err := walk(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot walk from the history of the group")
+	}
+
+	// satisfaction[item][m] is the share of member m's walks that visited item.
+	satisfaction := make(map[int][]float64)
+	memberVisits := make([]float64, len(members))
+	for _, w := range walks {
+		for _, step := range w.Steps {
+			if _, ok := satisfaction[step.Item]; !ok {
+				satisfaction[step.Item] = make([]float64, len(members))
+			}
+			for m, share := range shares[w.Origin] {
+				satisfaction[step.Item][m] += share
+				memberVisits[m] += share
+			}
+		}
+	}
+
+	scores := make(map[int]float64, len(satisfaction))
+	for item, itemSatisfaction := range satisfaction {
+		for m := range itemSatisfaction {
+			if memberVisits[m] > 0 {
+				itemSatisfaction[m] /= memberVisits[m]
+			}
+		}
+		scores[item] = aggregate(aggregation, members, itemSatisfaction)
+	}
+
+	return b.rankItems(sortByScore(scores), owned, k), nil
+}
+
+// aggregate combines the satisfaction of the members of a group.
+func aggregate(aggregation GroupAggregation, members []GroupMember, satisfaction []float64) float64 {
+	switch aggregation {
+	case LeastMisery:
+		score := math.Inf(1)
+		for _, s := range satisfaction {
+			score = math.Min(score, s)
+		}
+		return score
+	case MostPleasure:
+		score := math.Inf(-1)
+		for _, s := range satisfaction {
+			score = math.Max(score, s)
+		}
+		return score
+	default:
+		var score, totalWeight float64
+		for m, s := range satisfaction {
+			score += members[m].Weight * s
+			totalWeight += members[m].Weight
+		}
+		return score / totalWeight
+	}
+}
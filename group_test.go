@@ -0,0 +1,107 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Item 2 is the favourite of the fans of item 0, item 3 is liked by the fans
+// of item 1, and item 5 is liked by fans of both.
+var groupUsersToItems = [][]int{{0}, {1}, {0, 2}, {1, 3}, {0, 2}, {0, 5}, {1, 5}, {1, 3}, {0, 2}}
+
+type GroupCase struct {
+	Name        string
+	Members     []GroupMember
+	Aggregation GroupAggregation
+	Expected    int
+}
+
+var groupTable = []GroupCase{
+	{
+		Name:        "Least misery favours the item every member likes",
+		Members:     []GroupMember{{0, 1}, {1, 1}},
+		Aggregation: LeastMisery,
+		Expected:    5,
+	},
+	{
+		Name:        "Most pleasure favours the favourite item of a member",
+		Members:     []GroupMember{{0, 1}, {1, 1}},
+		Aggregation: MostPleasure,
+		Expected:    2,
+	},
+	{
+		Name:        "Average with a dominant member",
+		Members:     []GroupMember{{0, 1}, {1, 10}},
+		Aggregation: Average,
+		Expected:    3,
+	},
+}
+
+func TestRecommendForGroup(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.Draws = 5000
+	engine, err := New(WithCfg(cfg), WithInteractions(groupUsersToItems), WithSeed(42))
+	if err != nil {
+		t.Fatalf("RecommendForGroup: Bird initialization raised an error: %v", err)
+	}
+	bird := engine.(*Bird)
+
+	for _, ex := range groupTable {
+		recommended, err := bird.RecommendForGroup(ex.Members, ex.Aggregation, 1)
+		if err != nil {
+			t.Errorf("RecommendForGroup: %s: raised an error: %v", ex.Name, err)
+			continue
+		}
+		if len(recommended) != 1 || recommended[0] != ex.Expected {
+			t.Errorf("RecommendForGroup: %s: expected item %d, got %v", ex.Name, ex.Expected, recommended)
+		}
+	}
+
+	recommended, err := bird.RecommendForGroup([]GroupMember{{0, 1}, {1, 1}}, Average, 10)
+	if err != nil {
+		t.Fatalf("RecommendForGroup: raised an error: %v", err)
+	}
+	if contains(recommended, 0) || contains(recommended, 1) {
+		t.Errorf("RecommendForGroup: the items of the members should be excluded, got %v", recommended)
+	}
+
+	if _, err := bird.RecommendForGroup(nil, Average, 10); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("RecommendForGroup: expected an empty query error for an empty group, got %v", err)
+	}
+	if _, err := bird.RecommendForGroup([]GroupMember{{0, 1}, {9, 1}}, Average, 10); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("RecommendForGroup: expected an unknown user error, got %v", err)
+	}
+	if _, err := bird.RecommendForGroup([]GroupMember{{0, 0}}, Average, 10); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("RecommendForGroup: expected an invalid input error for a null weight, got %v", err)
+	}
+	if _, err := bird.RecommendForGroup([]GroupMember{{0, 1}}, GroupAggregation(3), 10); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("RecommendForGroup: expected an invalid configuration error, got %v", err)
+	}
+	if _, err := bird.RecommendForGroup([]GroupMember{{0, 1}}, Average, -1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("RecommendForGroup: expected an invalid configuration error for a negative k, got %v", err)
+	}
+}
+
+func TestWeaverRecommendForGroup(t *testing.T) {
+	// Member 0 trusts user 5 and member 1 trusts user 6, who both like item 5.
+	socialGraph := []map[int]float64{{5: 100}, {6: 100}, {}, {}, {}, {}, {}, {}, {}}
+	cfg := NewWeaverCfg()
+	cfg.Draws = 1000
+	engine, err := New(WithCfg(cfg.BirdCfg), WithInteractions(groupUsersToItems), WithSocialGraph(socialGraph, cfg), WithSeed(42))
+	if err != nil {
+		t.Fatalf("RecommendForGroup: Weaver initialization raised an error: %v", err)
+	}
+	weaver := engine.(*Weaver)
+
+	for _, aggregation := range []GroupAggregation{Average, LeastMisery, MostPleasure} {
+		recommended, err := weaver.RecommendForGroup([]GroupMember{{0, 1}, {1, 1}}, aggregation, 1)
+		if err != nil {
+			t.Errorf("RecommendForGroup: aggregation %d raised an error: %v", aggregation, err)
+			continue
+		}
+		if len(recommended) != 1 || recommended[0] != 5 {
+			t.Errorf("RecommendForGroup: aggregation %d: expected item 5, got %v", aggregation, recommended)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package birdland
+
+import "github.com/pkg/errors"
+
+type RadioCfg struct {
+	BatchSize      int     `yaml:"batch_size"`      // number of items emitted before the walks are performed again
+	NoRepeat       int     `yaml:"no_repeat"`       // number of most recent items that cannot be emitted again
+	GroupAttribute string  `yaml:"group_attribute"` // attribute that groups items, such as their artist
+	GroupSpacing   int     `yaml:"group_spacing"`   // number of most recent items whose groups cannot be emitted again
+	FoldWeight     float64 `yaml:"fold_weight"`     // weight in the query of the item that was just emitted
+	FoldDecay      float64 `yaml:"fold_decay"`      // factor applied to the weight of the emitted items at each emission
+}
+
+func NewRadioCfg() *RadioCfg {
+	cfg := RadioCfg{
+		BatchSize:    10,
+		NoRepeat:     50,
+		GroupSpacing: 0,
+		FoldWeight:   0.5,
+		FoldDecay:    0.8,
+	}
+
+	return &cfg
+}
+
+// Radio generates an endless stream of items from a seed query, for instance
+// to continue a playlist or run a radio station. Every item it emits is
+// folded back into the query with FoldWeight, a weight that decays with each
+// subsequent emission, so that the station slowly drifts away from the seed
+// without jumping around. The items of the seed are never emitted, and the
+// rules of Bird and Weaver engines apply. Radio is not safe for concurrent
+// use.
+type Radio struct {
+	Cfg *RadioCfg
+
+	engine     Engine
+	user       int
+	store      *AttributeStore
+	seed       []QueryItem
+	excluded   map[int]bool    // items of the seed
+	folded     map[int]float64 // weights of the emitted items in the query
+	recent     []int           // most recently emitted items, the last one at the end
+	candidates []int           // ranked items of the last walks
+	sinceWalks int             // number of items emitted since the last walks
+}
+
+// NewRadio returns a radio that walks on behalf of user from the seed query.
+// The attribute store is only needed to space out the items of the same
+// group, and can be nil otherwise.
+func NewRadio(cfg *RadioCfg, e Engine, seed []QueryItem, user int, store *AttributeStore) (*Radio, error) {
+	err := validateRadioCfg(cfg, store)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) == 0 {
+		return nil, errors.Wrap(ErrEmptyQuery, "the radio needs a seed")
+	}
+
+	excluded := make(map[int]bool, len(seed))
+	for _, q := range seed {
+		excluded[q.Item] = true
+	}
+
+	r := Radio{
+		Cfg:      cfg,
+		engine:   e,
+		user:     user,
+		store:    store,
+		seed:     seed,
+		excluded: excluded,
+		folded:   make(map[int]float64),
+	}
+
+	return &r, nil
+}
+
+// Next returns the next item of the stream. It returns an error that matches
+// ErrDeadEnd when no item complies with the repetition and spacing rules.
+func (r *Radio) Next() (int, error) {
+	if r.candidates == nil || r.sinceWalks >= r.Cfg.BatchSize {
+		err := r.walk()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	item, ok := r.pick()
+	if !ok {
+		// the candidates may be stale, try again with fresh walks
+		err := r.walk()
+		if err != nil {
+			return 0, err
+		}
+		item, ok = r.pick()
+		if !ok {
+			return 0, errors.Wrap(ErrDeadEnd, "the radio ran out of items")
+		}
+	}
+	r.emit(item)
+
+	return item, nil
+}
+
+// ranker is implemented by the engines that rank items with their rules.
+type ranker interface {
+	rankItems(recommended []int, excluded map[int]bool, k int) []int
+}
+
+// walk ranks the candidates from walks starting from the seed and the
+// emitted items, leaving out the items of the seed.
+func (r *Radio) walk() error {
+	query := make([]QueryItem, len(r.seed), len(r.seed)+len(r.folded))
+	copy(query, r.seed)
+	query = append(query, sortedQuery(r.folded)...)
+
+	walks, _, err := r.engine.Walk(query, r.user)
+	if err != nil {
+		return errors.Wrap(err, "cannot walk from the radio's query")
+	}
+	items, referrers := walks.Flatten()
+	candidates := RecommendItems(items, referrers)
+	if rk, ok := r.engine.(ranker); ok {
+		r.candidates = rk.rankItems(candidates, r.excluded, len(candidates))
+	} else {
+		r.candidates = filterRecommendations(candidates, r.excluded, len(candidates))
+	}
+	r.sinceWalks = 0
+
+	return nil
+}
+
+// pick returns the best candidate that complies with the repetition and
+// spacing rules.
+func (r *Radio) pick() (int, bool) {
+	blocked := make(map[int]bool)
+	for i := len(r.recent) - 1; i >= 0 && i >= len(r.recent)-r.Cfg.NoRepeat; i-- {
+		blocked[r.recent[i]] = true
+	}
+
+	blockedGroups := make(map[string]bool)
+	for i := len(r.recent) - 1; i >= 0 && i >= len(r.recent)-r.Cfg.GroupSpacing; i-- {
+		for _, g := range r.store.Get(r.recent[i], r.Cfg.GroupAttribute) {
+			blockedGroups[g] = true
+		}
+	}
+
+	for _, item := range r.candidates {
+		if blocked[item] || r.inGroups(item, blockedGroups) {
+			continue
+		}
+		return item, true
+	}
+
+	return 0, false
+}
+
+func (r *Radio) inGroups(item int, groups map[string]bool) bool {
+	if len(groups) == 0 {
+		return false
+	}
+	for _, g := range r.store.Get(item, r.Cfg.GroupAttribute) {
+		if groups[g] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// emit records the emitted item and folds it back into the query.
+func (r *Radio) emit(item int) {
+	for i, w := range r.folded {
+		w *= r.Cfg.FoldDecay
+		if w < 1e-3*r.Cfg.FoldWeight {
+			delete(r.folded, i)
+			continue
+		}
+		r.folded[i] = w
+	}
+	if r.Cfg.FoldWeight > 0 {
+		r.folded[item] += r.Cfg.FoldWeight
+	}
+
+	r.recent = append(r.recent, item)
+	window := r.Cfg.NoRepeat
+	if r.Cfg.GroupSpacing > window {
+		window = r.Cfg.GroupSpacing
+	}
+	if len(r.recent) > window {
+		r.recent = append(r.recent[:0], r.recent[len(r.recent)-window:]...)
+	}
+	r.sinceWalks++
+}
+
+// validateRadioCfg checks the parameters of the radio.
+func validateRadioCfg(cfg *RadioCfg, store *AttributeStore) error {
+	if cfg.BatchSize < 1 {
+		return errors.Wrap(ErrInvalidConfig, "the batch size must be greater than or equal to 1")
+	}
+	if cfg.NoRepeat < 0 || cfg.GroupSpacing < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the repetition windows must be positive")
+	}
+	if cfg.GroupSpacing > 0 && (store == nil || cfg.GroupAttribute == "") {
+		return errors.Wrap(ErrInvalidConfig, "spacing out groups requires an attribute store and a group attribute")
+	}
+	if cfg.FoldWeight < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the weight of the emitted items must be positive")
+	}
+	if cfg.FoldDecay < 0 || cfg.FoldDecay > 1 {
+		return errors.Wrap(ErrInvalidConfig, "the decay of the emitted items must be between 0 and 1")
+	}
+
+	return nil
+}
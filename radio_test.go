@@ -0,0 +1,151 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Items 0 to 5 are connected. Items 0, 1 and 2 are by artist A, items 3, 4
+// and 5 by artist B.
+var radioUsersToItems = [][]int{{0, 1, 3}, {1, 2, 4}, {2, 0, 5}, {3, 4, 5}, {0, 5}}
+
+func newRadioBird(t *testing.T) (*Bird, *AttributeStore) {
+	cfg := NewBirdCfg()
+	cfg.Draws = 200
+	cfg.Depth = 2
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1, 1, 1}, radioUsersToItems)
+	if err != nil {
+		t.Fatalf("Radio: Bird initialization raised an error: %v", err)
+	}
+
+	store := NewAttributeStore()
+	for item := 0; item < 6; item++ {
+		store.Set(item, "artist", string(rune('A'+item/3)))
+	}
+
+	return bird, store
+}
+
+func TestRadioNoRepeat(t *testing.T) {
+	bird, _ := newRadioBird(t)
+	cfg := NewRadioCfg()
+	cfg.BatchSize = 2
+	cfg.NoRepeat = 4
+	radio, err := NewRadio(cfg, bird, []QueryItem{{0, 1}}, 0, nil)
+	if err != nil {
+		t.Fatalf("Radio: initialization raised an error: %v", err)
+	}
+
+	var stream []int
+	for i := 0; i < 30; i++ {
+		item, err := radio.Next()
+		if err != nil {
+			t.Fatalf("Radio: raised an error after %d items: %v", i, err)
+		}
+		stream = append(stream, item)
+	}
+	for i := range stream {
+		for j := i + 1; j < len(stream) && j <= i+4; j++ {
+			if stream[i] == stream[j] {
+				t.Fatalf("Radio: item %d was repeated within the no-repeat window: %v", stream[i], stream)
+			}
+		}
+	}
+
+	if radio.folded[stream[29]] < cfg.FoldWeight || radio.folded[stream[28]] < cfg.FoldWeight*cfg.FoldDecay {
+		t.Errorf("Radio: the emitted items should be folded into the query, got %v", radio.folded)
+	}
+}
+
+func TestRadioGroupSpacing(t *testing.T) {
+	bird, store := newRadioBird(t)
+	cfg := NewRadioCfg()
+	cfg.NoRepeat = 0
+	cfg.GroupAttribute = "artist"
+	cfg.GroupSpacing = 1
+	radio, err := NewRadio(cfg, bird, []QueryItem{{0, 1}}, 0, store)
+	if err != nil {
+		t.Fatalf("Radio: initialization raised an error: %v", err)
+	}
+
+	previous := -1
+	for i := 0; i < 20; i++ {
+		item, err := radio.Next()
+		if err != nil {
+			t.Fatalf("Radio: raised an error after %d items: %v", i, err)
+		}
+		if previous >= 0 && previous/3 == item/3 {
+			t.Fatalf("Radio: items %d and %d by the same artist were emitted in a row", previous, item)
+		}
+		previous = item
+	}
+}
+
+func TestRadioExhausted(t *testing.T) {
+	bird, _ := newRadioBird(t)
+	cfg := NewRadioCfg()
+	cfg.NoRepeat = 6
+	radio, err := NewRadio(cfg, bird, []QueryItem{{0, 1}}, 0, nil)
+	if err != nil {
+		t.Fatalf("Radio: initialization raised an error: %v", err)
+	}
+
+	// the 5 items other than the seed are emitted once
+	for i := 0; i < 5; i++ {
+		if _, err := radio.Next(); err != nil {
+			t.Fatalf("Radio: raised an error after %d items: %v", i, err)
+		}
+	}
+	if _, err := radio.Next(); !errors.Is(err, ErrDeadEnd) {
+		t.Errorf("Radio: expected a dead end error once every item was emitted, got %v", err)
+	}
+}
+
+func TestRadioSeedAndRules(t *testing.T) {
+	bird, store := newRadioBird(t)
+	cfg := NewRadioCfg()
+	cfg.NoRepeat = 0
+	radio, err := NewRadio(cfg, bird, []QueryItem{{0, 1}, {1, 1}}, 0, nil)
+	if err != nil {
+		t.Fatalf("Radio: initialization raised an error: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		item, err := radio.Next()
+		if err != nil {
+			t.Fatalf("Radio: raised an error after %d items: %v", i, err)
+		}
+		if item == 0 || item == 1 {
+			t.Fatalf("Radio: the seed item %d was emitted", item)
+		}
+	}
+
+	bird.Attributes = store
+	bird.Rules = []Rule{{Kind: Exclude, Attribute: "artist", Value: "B"}}
+	radio, err = NewRadio(cfg, bird, []QueryItem{{0, 1}}, 0, nil)
+	if err != nil {
+		t.Fatalf("Radio: initialization raised an error: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		item, err := radio.Next()
+		if err != nil {
+			t.Fatalf("Radio: raised an error after %d items: %v", i, err)
+		}
+		if item != 1 && item != 2 {
+			t.Fatalf("Radio: expected items by artist A other than the seed, got %d", item)
+		}
+	}
+}
+
+func TestRadioCfg(t *testing.T) {
+	bird, _ := newRadioBird(t)
+
+	cfg := NewRadioCfg()
+	cfg.GroupSpacing = 2
+	if _, err := NewRadio(cfg, bird, []QueryItem{{0, 1}}, 0, nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Radio: group spacing without attributes should raise an invalid configuration error, got %v", err)
+	}
+	if _, err := NewRadio(NewRadioCfg(), bird, nil, 0, nil); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("Radio: a radio without seed should raise an empty query error, got %v", err)
+	}
+}
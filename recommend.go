@@ -1,7 +1,10 @@
 package birdland
 
 import (
+	"math"
 	"sort"
+
+	"github.com/pkg/errors"
 )
 
 type Pair struct {
@@ -110,3 +113,144 @@ func RecommendTrust(items, referrers []int) []int {
 
 	return recommendedItems
 }
+
+type ScoredPair struct {
+	Object int
+	Score  float64
+}
+
+type ScoredPairList []ScoredPair // float counterpart of PairList
+
+func (p ScoredPairList) Len() int           { return len(p) }
+func (p ScoredPairList) Less(i, j int) bool { return p[i].Score < p[j].Score }
+func (p ScoredPairList) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// DepthDecay returns the weight attributed to a visit that happened at the
+// given depth of a walk. Depths start at 1.
+type DepthDecay func(depth int) float64
+
+// GeometricDecay returns a DepthDecay that discounts each additional hop by
+// factor, so that a visit at depth d weighs factor^(d-1).
+func GeometricDecay(factor float64) DepthDecay {
+	return func(depth int) float64 {
+		return math.Pow(factor, float64(depth-1))
+	}
+}
+
+// RecommendDepthDiscounted recommends the items in descending order of their
+// number of visits, each visit being weighted by decay(depth). Discounting far
+// hops prevents recommendations from drifting towards globally popular items
+// as the depth of the walks grows.
+func RecommendDepthDiscounted(walks Walks, decay DepthDecay) []int {
+	itemScores := make(map[int]float64)
+	for _, walk := range walks {
+		for d, step := range walk.Steps {
+			itemScores[step.Item] += decay(d + 1)
+		}
+	}
+
+	return sortByScore(itemScores)
+}
+
+// RecommendTrustDiscounted is the depth-discounted version of RecommendTrust.
+// Referrers are trusted in proportion to their discounted number of
+// traversals, and items are recommended by descending order of the
+// cumulated, discounted trust of their referrers.
+func RecommendTrustDiscounted(walks Walks, decay DepthDecay) []int {
+	referrerTrust := make(map[int]float64)
+	for _, walk := range walks {
+		for d, step := range walk.Steps {
+			referrerTrust[step.Referrer] += decay(d + 1)
+		}
+	}
+
+	itemScores := make(map[int]float64)
+	for _, walk := range walks {
+		for d, step := range walk.Steps {
+			itemScores[step.Item] += decay(d+1) * referrerTrust[step.Referrer]
+		}
+	}
+
+	return sortByScore(itemScores)
+}
+
+// RecommendDegreeNormalized recommends the items in descending order of their
+// number of visits divided by their degree, the number of users who
+// interacted with them, raised to the power beta. Random walks visit items in
+// proportion to their degree, so raw visit counts favour popular items; beta
+// tunes the tradeoff between popularity (beta = 0 is RecommendMostVisited)
+// and novelty (beta = 1 removes the advantage of popular items).
+func RecommendDegreeNormalized(items []int, itemsToUsers [][]int, beta float64) []int {
+	visits := make(map[int]float64)
+	for _, item := range items {
+		visits[item]++
+	}
+
+	itemScores := make(map[int]float64, len(visits))
+	for item, count := range visits {
+		itemScores[item] = count / math.Pow(float64(len(itemsToUsers[item])), beta)
+	}
+
+	return sortByScore(itemScores)
+}
+
+// RecommendLift recommends the items in descending order of the lift of their
+// visits over a background distribution of visits, such as the one returned
+// by EstimateBackground: the share of the visits an item received divided by
+// its background probability raised to the power beta. Items that are
+// visited whatever the query are thus demoted in favour of the items specific
+// to the query.
+func RecommendLift(items []int, background []float64, beta float64) []int {
+	visits := make(map[int]float64)
+	for _, item := range items {
+		visits[item]++
+	}
+
+	itemScores := make(map[int]float64, len(visits))
+	for item, count := range visits {
+		itemScores[item] = count / float64(len(items)) / math.Pow(background[item], beta)
+	}
+
+	return sortByScore(itemScores)
+}
+
+// sortByScore returns the objects by descending order of score.
+func sortByScore(scores map[int]float64) []int {
+	pairList := make(ScoredPairList, 0, len(scores))
+	for object, score := range scores {
+		pairList = append(pairList, ScoredPair{object, score})
+	}
+
+	sort.Sort(sort.Reverse(pairList))
+	recommended := make([]int, len(pairList))
+	for i, pair := range pairList {
+		recommended[i] = pair.Object
+	}
+
+	return recommended
+}
+
+// validateK checks the number of recommendations requested.
+func validateK(k int) error {
+	if k < 0 {
+		return errors.Wrapf(ErrInvalidConfig, "cannot recommend %d items", k)
+	}
+	return nil
+}
+
+// filterRecommendations removes the excluded objects from the recommendations
+// and keeps at most k of them.
+func filterRecommendations(recommended []int, excluded map[int]bool, k int) []int {
+	filtered := make([]int, 0, k)
+	for _, object := range recommended {
+		if len(filtered) == k {
+			break
+		}
+		if excluded[object] {
+			continue
+		}
+		filtered = append(filtered, object)
+	}
+
+	return filtered
+}
@@ -103,3 +103,143 @@ func TestRecommendTrust(t *testing.T) {
 		}
 	}
 }
+
+type DepthDiscountedCase struct {
+	Name     string
+	Walks    Walks
+	Decay    DepthDecay
+	Expected []int
+}
+
+var discountedWalks = Walks{
+	{Origin: 0, Steps: []Step{{Referrer: 10, Item: 2}, {Referrer: 11, Item: 3}, {Referrer: 12, Item: 1}}},
+	{Origin: 0, Steps: []Step{{Referrer: 10, Item: 2}, {Referrer: 13, Item: 3}, {Referrer: 12, Item: 1}}},
+	{Origin: 0, Steps: []Step{{Referrer: 14, Item: 4}, {Referrer: 15, Item: 4}, {Referrer: 12, Item: 1}}},
+}
+
+var depthDiscounted_table = []DepthDiscountedCase{
+	{
+		Name:     "Empty input",
+		Walks:    Walks{},
+		Decay:    GeometricDecay(0.5),
+		Expected: []int{},
+	},
+	{
+		Name:     "Geometric decay",
+		Walks:    discountedWalks,
+		Decay:    GeometricDecay(0.5),
+		Expected: []int{2, 4, 3, 1},
+	},
+	{
+		Name:     "User-supplied decay that favours far hops",
+		Walks:    discountedWalks,
+		Decay:    func(depth int) float64 { return float64(depth * depth) },
+		Expected: []int{1, 3, 4, 2},
+	},
+}
+
+var trustDiscounted_table = []DepthDiscountedCase{
+	{
+		Name:     "Geometric decay",
+		Walks:    discountedWalks,
+		Decay:    GeometricDecay(0.5),
+		Expected: []int{2, 4, 1, 3},
+	},
+}
+
+func TestRecommendDepthDiscounted(t *testing.T) {
+	for _, ex := range depthDiscounted_table {
+		recommended := RecommendDepthDiscounted(ex.Walks, ex.Decay)
+		if len(recommended) != len(ex.Expected) {
+			t.Errorf("RecommendDepthDiscounted: %s: discrepancy in the length of the recommendations: expected %d, got %d", ex.Name, len(ex.Expected), len(recommended))
+		}
+		for i, r := range recommended {
+			if r != ex.Expected[i] {
+				t.Errorf("RecommendDepthDiscounted: %s: expected %d, got %d", ex.Name, ex.Expected, recommended)
+				break
+			}
+		}
+	}
+}
+
+func TestRecommendTrustDiscounted(t *testing.T) {
+	for _, ex := range trustDiscounted_table {
+		recommended := RecommendTrustDiscounted(ex.Walks, ex.Decay)
+		if len(recommended) != len(ex.Expected) {
+			t.Errorf("RecommendTrustDiscounted: %s: discrepancy in the length of the recommendations: expected %d, got %d", ex.Name, len(ex.Expected), len(recommended))
+		}
+		for i, r := range recommended {
+			if r != ex.Expected[i] {
+				t.Errorf("RecommendTrustDiscounted: %s: expected %d, got %d", ex.Name, ex.Expected, recommended)
+				break
+			}
+		}
+	}
+}
+
+type PopularityCase struct {
+	Name     string
+	Beta     float64
+	Expected []int
+}
+
+var popularityItems = []int{0, 0, 1, 2, 0, 1, 0, 2, 0, 1, 0}
+
+// item 0 is a hub, item 1 is in the long tail
+var degreeNormalized_table = []PopularityCase{
+	{
+		Name:     "No normalization",
+		Beta:     0,
+		Expected: []int{0, 1, 2},
+	},
+	{
+		Name:     "Normalization by degree",
+		Beta:     1,
+		Expected: []int{1, 0, 2},
+	},
+}
+
+var lift_table = []PopularityCase{
+	{
+		Name:     "No normalization",
+		Beta:     0,
+		Expected: []int{0, 1, 2},
+	},
+	{
+		Name:     "Lift over the background",
+		Beta:     1,
+		Expected: []int{1, 0, 2},
+	},
+}
+
+func TestRecommendDegreeNormalized(t *testing.T) {
+	itemsToUsers := [][]int{{0, 1, 2, 3, 4, 5}, {0}, {0, 1, 2, 3}}
+	for _, ex := range degreeNormalized_table {
+		recommended := RecommendDegreeNormalized(popularityItems, itemsToUsers, ex.Beta)
+		if len(recommended) != len(ex.Expected) {
+			t.Errorf("RecommendDegreeNormalized: %s: discrepancy in the length of the recommendations: expected %d, got %d", ex.Name, len(ex.Expected), len(recommended))
+		}
+		for i, r := range recommended {
+			if r != ex.Expected[i] {
+				t.Errorf("RecommendDegreeNormalized: %s: expected %d, got %d", ex.Name, ex.Expected, recommended)
+				break
+			}
+		}
+	}
+}
+
+func TestRecommendLift(t *testing.T) {
+	background := []float64{0.6, 0.1, 0.3}
+	for _, ex := range lift_table {
+		recommended := RecommendLift(popularityItems, background, ex.Beta)
+		if len(recommended) != len(ex.Expected) {
+			t.Errorf("RecommendLift: %s: discrepancy in the length of the recommendations: expected %d, got %d", ex.Name, len(ex.Expected), len(recommended))
+		}
+		for i, r := range recommended {
+			if r != ex.Expected[i] {
+				t.Errorf("RecommendLift: %s: expected %d, got %d", ex.Name, ex.Expected, recommended)
+				break
+			}
+		}
+	}
+}
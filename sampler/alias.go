@@ -1,7 +1,6 @@
 package sampler
 
 import (
-	"fmt"
 	"math/rand"
 
 	"github.com/pkg/errors"
@@ -19,7 +18,7 @@ type AliasSampler struct {
 func NewAliasSampler(source *rand.Rand, weights []float64) (*AliasSampler, error) {
 
 	if len(weights) == 0 {
-		return &AliasSampler{}, fmt.Errorf("weights is an empty slice")
+		return &AliasSampler{}, ErrEmptyWeights
 	}
 
 	probabilityTable, aliasTable, err := VoseInitialization(weights)
@@ -56,6 +55,33 @@ func (t *AliasSampler) Sample(numSamples int) []int {
 	return samples
 }
 
+// Reset replaces the distribution the sampler draws from with the one given
+// by weights. The probability and alias tables are reused when they are large
+// enough, so samplers can be updated frequently without allocating new ones.
+// The sampler is left unchanged if the weights are invalid.
+func (t *AliasSampler) Reset(weights []float64) error {
+
+	if len(weights) == 0 {
+		return ErrEmptyWeights
+	}
+
+	normalizedWeights, err := normalize(weights)
+	if err != nil {
+		return errors.Wrap(err, "cannot reset the alias sampler")
+	}
+
+	n := len(weights)
+	if cap(t.ProbabilityTable) < n || cap(t.AliasTable) < n {
+		t.ProbabilityTable = make([]float64, n)
+		t.AliasTable = make([]int, n)
+	}
+	t.ProbabilityTable = t.ProbabilityTable[:n]
+	t.AliasTable = t.AliasTable[:n]
+	fillTables(normalizedWeights, t.ProbabilityTable, t.AliasTable)
+
+	return nil
+}
+
 // VoseInitialization initialises the probability and alias tables using Vose's
 // method. Vose's method runs in O(n) and is more numerically stable than
 // alternatives. See http://www.keithschwarz.com/darts-dice-coins/ for more
@@ -67,6 +93,22 @@ func VoseInitialization(weights []float64) ([]float64, []int, error) {
 		return nil, nil, errors.Wrap(err, "cannot normalize input weights")
 	}
 
+	aliasTable := make([]int, len(weights))
+	probabilityTable := make([]float64, len(weights))
+	fillTables(normalizedWeights, probabilityTable, aliasTable)
+
+	return probabilityTable, aliasTable, nil
+}
+
+// fillTables fills the probability and alias tables, which must have the
+// same length as the normalized weights, using Vose's method.
+func fillTables(normalizedWeights []float64, probabilityTable []float64, aliasTable []int) {
+
+	for i := range aliasTable {
+		aliasTable[i] = 0
+		probabilityTable[i] = 0
+	}
+
 	small := make([]int, 0, len(normalizedWeights))
 	large := make([]int, 0, len(normalizedWeights))
 	for i, w := range normalizedWeights {
@@ -77,8 +119,6 @@ func VoseInitialization(weights []float64) ([]float64, []int, error) {
 		}
 	}
 
-	aliasTable := make([]int, len(weights))
-	probabilityTable := make([]float64, len(weights))
 	var g, l int
 	for (len(small) > 0) && (len(large) > 0) {
 		l, small = small[0], small[1:]
@@ -103,8 +143,6 @@ func VoseInitialization(weights []float64) ([]float64, []int, error) {
 		l, small = small[0], small[1:]
 		probabilityTable[g] = 1
 	}
-
-	return probabilityTable, aliasTable, nil
 }
 
 // normalize prepares the weights for the algorithm's initialization.
@@ -112,11 +150,15 @@ func normalize(weights []float64) ([]float64, error) {
 	var sum float64
 	for _, w := range weights {
 		if w < 0 {
-			return nil, fmt.Errorf("found negative weight %v", w)
+			return nil, errors.Wrapf(ErrNegativeWeight, "found %v", w)
 		}
 		sum += w
 	}
 
+	if sum == 0 {
+		return nil, ErrNullWeights
+	}
+
 	n := len(weights)
 	normalizedWeights := make([]float64, n)
 	for i, weight := range weights {
@@ -73,6 +73,13 @@ var aliassampler_table = []AliasSamplerCase{
 		Samples:    []int{},
 		Valid:      false,
 	},
+	{
+		Name:       "Zero weights",
+		NumSamples: 0,
+		Weights:    []float64{0, 0, 0},
+		Samples:    []int{},
+		Valid:      false,
+	},
 	{
 		Name:       "Zero samples",
 		NumSamples: 0,
@@ -153,6 +160,47 @@ func TestAliasSampling(t *testing.T) {
 	}
 }
 
+func TestAliasSamplerReset(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	s, err := NewAliasSampler(r, []float64{1, 1, 1})
+	if err != nil {
+		t.Fatalf("alias sampler: reset: init raised an error: %v", err)
+	}
+
+	for _, ex := range aliassampler_table {
+		if !ex.Valid {
+			if err := s.Reset(ex.Weights); err == nil {
+				t.Errorf("alias sampler: reset: %s should have raised an error, got none instead", ex.Name)
+			}
+			continue
+		}
+		if err := s.Reset(ex.Weights); err != nil {
+			t.Errorf("alias sampler: reset: %s should not have raised an error, raised %v instead", ex.Name, err)
+			continue
+		}
+
+		expected, err := NewAliasSampler(r, ex.Weights)
+		if err != nil {
+			t.Fatalf("alias sampler: reset: %s: init raised an error: %v", ex.Name, err)
+		}
+		for i := range expected.ProbabilityTable {
+			if s.ProbabilityTable[i] != expected.ProbabilityTable[i] || s.AliasTable[i] != expected.AliasTable[i] {
+				t.Errorf("alias sampler: reset: %s: expected tables %v and %v, got %v and %v", ex.Name,
+					expected.ProbabilityTable, expected.AliasTable, s.ProbabilityTable, s.AliasTable)
+				break
+			}
+		}
+	}
+
+	probabilityTable := s.ProbabilityTable
+	if err := s.Reset([]float64{2, 1}); err != nil {
+		t.Fatalf("alias sampler: reset: raised an error: %v", err)
+	}
+	if &s.ProbabilityTable[0] != &probabilityTable[0] {
+		t.Errorf("alias sampler: reset: the tables should have been reused")
+	}
+}
+
 // Benchmarks
 // ////////////////////////////////////////////////////////////////////////////
 
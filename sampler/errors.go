@@ -0,0 +1,11 @@
+package sampler
+
+import "github.com/pkg/errors"
+
+// Errors returned by the samplers' constructors. They are wrapped with
+// context and can be identified with errors.Is.
+var (
+	ErrEmptyWeights   = errors.New("weights is an empty slice")
+	ErrNegativeWeight = errors.New("negative weight")
+	ErrNullWeights    = errors.New("all weights are null")
+)
@@ -1,7 +1,6 @@
 package sampler
 
 import (
-	"fmt"
 	"math/rand"
 	"sort"
 
@@ -18,7 +17,7 @@ type TowerSampler struct {
 func NewTowerSampler(source *rand.Rand, weights []float64) (*TowerSampler, error) {
 
 	if len(weights) == 0 {
-		return &TowerSampler{}, fmt.Errorf("weights is an empty slice")
+		return &TowerSampler{}, ErrEmptyWeights
 	}
 
 	cumulative, err := accumulate(weights)
@@ -51,14 +50,14 @@ func accumulate(weights []float64) ([]float64, error) {
 	cumulativeSum := make([]float64, len(weights))
 	for i, weight := range weights {
 		if weight < 0 {
-			return nil, fmt.Errorf("negative weight: %g", weight)
+			return nil, errors.Wrapf(ErrNegativeWeight, "found %g", weight)
 		}
 		sum += weight
 		cumulativeSum[i] = sum
 	}
 
 	if sum == 0 {
-		return nil, fmt.Errorf("all weights are null")
+		return nil, ErrNullWeights
 	}
 
 	for i, cumSum := range cumulativeSum {
@@ -0,0 +1,131 @@
+package birdland
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EventKind is the type of a listening event.
+type EventKind int
+
+const (
+	Play EventKind = iota // the item was played
+	Like                  // the item was liked
+	Skip                  // the item was skipped, a negative signal
+)
+
+// Event is something a listener did with an item during a session.
+type Event struct {
+	Item int
+	Kind EventKind
+	Time time.Time
+}
+
+type SessionCfg struct {
+	Size       int           `yaml:"size"`      // number of most recent events kept in the session
+	HalfLife   time.Duration `yaml:"half_life"` // time for the weight of an event to halve, 0 disables the decay
+	PlayWeight float64       `yaml:"play_weight"`
+	LikeWeight float64       `yaml:"like_weight"`
+	SkipWeight float64       `yaml:"skip_weight"`
+}
+
+func NewSessionCfg() *SessionCfg {
+	cfg := SessionCfg{
+		Size:       20,
+		HalfLife:   5 * time.Minute,
+		PlayWeight: 1,
+		LikeWeight: 3,
+		SkipWeight: 1,
+	}
+
+	return &cfg
+}
+
+// Session turns the stream of events of a listening session into queries. It
+// keeps the Size most recent events; plays and likes make up the query while
+// skips make up the negative query, to be used with negative feedback. Each
+// event is weighted by the weight of its kind, decayed with its age so that
+// the most recent events dominate.
+type Session struct {
+	Cfg    *SessionCfg
+	events []Event // sorted by time
+}
+
+// NewSession returns an empty session.
+func NewSession(cfg *SessionCfg) (*Session, error) {
+	err := validateSessionCfg(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{Cfg: cfg}, nil
+}
+
+// Add records events in the session and forgets the oldest ones if the
+// session holds more than Size events. Events do not need to be in order.
+func (s *Session) Add(events ...Event) {
+	s.events = append(s.events, events...)
+	sort.SliceStable(s.events, func(i, j int) bool { return s.events[i].Time.Before(s.events[j].Time) })
+	if len(s.events) > s.Cfg.Size {
+		s.events = append(s.events[:0], s.events[len(s.events)-s.Cfg.Size:]...)
+	}
+}
+
+// Events returns the events of the session, from the oldest to the most
+// recent.
+func (s *Session) Events() []Event {
+	return s.events
+}
+
+// Query returns the query and the negative query of the session at time
+// now. The weights of the events involving the same item are summed, and
+// items are sorted by id.
+func (s *Session) Query(now time.Time) ([]QueryItem, []QueryItem) {
+	positive := make(map[int]float64)
+	negative := make(map[int]float64)
+	for _, e := range s.events {
+		decay := decayFactor(now.Sub(e.Time), s.Cfg.HalfLife)
+		switch e.Kind {
+		case Play:
+			positive[e.Item] += s.Cfg.PlayWeight * decay
+		case Like:
+			positive[e.Item] += s.Cfg.LikeWeight * decay
+		case Skip:
+			negative[e.Item] += s.Cfg.SkipWeight * decay
+		}
+	}
+
+	return sortedQuery(positive), sortedQuery(negative)
+}
+
+// sortedQuery converts item weights to a query sorted by item, leaving out
+// the items with a null weight.
+func sortedQuery(weights map[int]float64) []QueryItem {
+	query := make([]QueryItem, 0, len(weights))
+	for item, w := range weights {
+		if w > 0 {
+			query = append(query, QueryItem{Item: item, Weight: w})
+		}
+	}
+	sort.Slice(query, func(i, j int) bool { return query[i].Item < query[j].Item })
+
+	return query
+}
+
+// validateSessionCfg checks that the session keeps events and that their
+// weights are positive.
+func validateSessionCfg(cfg *SessionCfg) error {
+	if cfg.Size < 1 {
+		return errors.Wrap(ErrInvalidConfig, "the size of the session must be greater than or equal to 1")
+	}
+	if cfg.HalfLife < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the half-life must be positive")
+	}
+	if cfg.PlayWeight < 0 || cfg.LikeWeight < 0 || cfg.SkipWeight < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the weights of the events must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package birdland
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestSessionQuery(t *testing.T) {
+	cfg := NewSessionCfg()
+	cfg.Size = 4
+	cfg.HalfLife = time.Minute
+	session, err := NewSession(cfg)
+	if err != nil {
+		t.Fatalf("SessionQuery: initialization raised an error: %v", err)
+	}
+
+	session.Add(
+		Event{Item: 0, Kind: Play, Time: decayNow.Add(-5 * time.Minute)}, // forgotten
+		Event{Item: 1, Kind: Play, Time: decayNow.Add(-2 * time.Minute)},
+		Event{Item: 3, Kind: Skip, Time: decayNow.Add(-time.Minute)},
+	)
+	session.Add(
+		Event{Item: 2, Kind: Like, Time: decayNow},
+		Event{Item: 1, Kind: Play, Time: decayNow.Add(-3 * time.Minute)},
+	)
+
+	if events := session.Events(); len(events) != 4 || events[0].Item != 1 || events[3].Item != 2 {
+		t.Errorf("SessionQuery: expected the 4 most recent events in order, got %v", events)
+	}
+
+	query, negative := session.Query(decayNow)
+	expected := []QueryItem{{1, 0.25 + 0.125}, {2, 3}}
+	if len(query) != len(expected) {
+		t.Fatalf("SessionQuery: expected the query %v, got %v", expected, query)
+	}
+	for i := range expected {
+		if query[i].Item != expected[i].Item || math.Abs(query[i].Weight-expected[i].Weight) > 1e-9 {
+			t.Errorf("SessionQuery: expected the query %v, got %v", expected, query)
+			break
+		}
+	}
+	if len(negative) != 1 || negative[0].Item != 3 || math.Abs(negative[0].Weight-0.5) > 1e-9 {
+		t.Errorf("SessionQuery: expected the negative query [{3 0.5}], got %v", negative)
+	}
+
+	cfg = NewSessionCfg()
+	cfg.SkipWeight = -1
+	if _, err := NewSession(cfg); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("SessionQuery: a negative weight should raise an invalid configuration error, got %v", err)
+	}
+}
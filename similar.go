@@ -0,0 +1,66 @@
+package birdland
+
+import "github.com/pkg/errors"
+
+// SimilarItems returns the k items most visited by random walks starting from
+// item, the item itself excluded ("more like this").
+func (b *Bird) SimilarItems(item, k int) ([]int, error) {
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+	if item < 0 || item >= len(b.ItemWeights) {
+		return nil, &UnknownItemError{Item: item}
+	}
+
+	walks, _, err := b.ProcessWalks([]QueryItem{{Item: item, Weight: 1}})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot walk from item %d", item)
+	}
+	items, _ := walks.Flatten()
+
+	return b.rankItems(RecommendMostVisited(items), map[int]bool{item: true}, k), nil
+}
+
+// SimilarItemsTable holds the precomputed neighbours of every item, sorted by
+// decreasing similarity. Items no one has interacted with have no neighbours.
+// It is typically built in a batch job and served directly.
+type SimilarItemsTable [][]int
+
+// BuildSimilarItemsTable materialises the n most similar items of every item
+// of the graph. The engine's rules are applied when the table is built.
+func (b *Bird) BuildSimilarItemsTable(n int) (SimilarItemsTable, error) {
+	table := make(SimilarItemsTable, len(b.ItemWeights))
+	for item := range table {
+		if len(b.ItemsToUsers[item]) == 0 || b.ItemWeights[item] == 0 {
+			continue
+		}
+
+		similar, err := b.SimilarItems(item, n)
+		if err != nil {
+			return nil, err
+		}
+		table[item] = similar
+	}
+
+	return table, nil
+}
+
+// SimilarItems returns the k most similar items of item, or all the
+// precomputed ones if there are fewer than k.
+func (t SimilarItemsTable) SimilarItems(item, k int) ([]int, error) {
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+	if item < 0 || item >= len(t) {
+		return nil, &UnknownItemError{Item: item}
+	}
+
+	similar := t[item]
+	if k < len(similar) {
+		similar = similar[:k]
+	}
+
+	return similar, nil
+}
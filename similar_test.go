@@ -0,0 +1,88 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Two clusters of items, {0, 1, 2} and {3, 4}, and an item no one has
+// interacted with.
+var similarUsersToItems = [][]int{{0, 1}, {1, 2}, {0, 2}, {3, 4}, {4, 3}}
+
+func TestSimilarItems(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.Draws = 100
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1, 1, 1}, similarUsersToItems)
+	if err != nil {
+		t.Fatalf("SimilarItems: Bird initialization raised an error: %v", err)
+	}
+
+	similar, err := bird.SimilarItems(0, 5)
+	if err != nil {
+		t.Fatalf("SimilarItems: raised an error: %v", err)
+	}
+	if len(similar) != 2 {
+		t.Fatalf("SimilarItems: expected items 1 and 2, got %v", similar)
+	}
+	for _, item := range similar {
+		if item != 1 && item != 2 {
+			t.Errorf("SimilarItems: expected items 1 and 2, got %v", similar)
+		}
+	}
+
+	similar, err = bird.SimilarItems(3, 1)
+	if err != nil {
+		t.Fatalf("SimilarItems: raised an error: %v", err)
+	}
+	if len(similar) != 1 || similar[0] != 4 {
+		t.Errorf("SimilarItems: expected item 4, got %v", similar)
+	}
+
+	if _, err := bird.SimilarItems(6, 1); err == nil {
+		t.Errorf("SimilarItems: an unknown item should have raised an error")
+	}
+	if _, err := bird.SimilarItems(0, -1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("SimilarItems: expected an invalid configuration error for a negative k, got %v", err)
+	}
+}
+
+func TestSimilarItemsTable(t *testing.T) {
+	cfg := NewBirdCfg()
+	cfg.Draws = 100
+	bird, err := NewBird(cfg, []float64{1, 1, 1, 1, 1, 1}, similarUsersToItems)
+	if err != nil {
+		t.Fatalf("SimilarItemsTable: Bird initialization raised an error: %v", err)
+	}
+
+	table, err := bird.BuildSimilarItemsTable(1)
+	if err != nil {
+		t.Fatalf("SimilarItemsTable: building the table raised an error: %v", err)
+	}
+
+	// only one neighbour is kept for each item
+	expected := [][]int{{1, 2}, {0, 2}, {0, 1}, {4}, {3}, {}}
+	for item, candidates := range expected {
+		similar, err := table.SimilarItems(item, 10)
+		if err != nil {
+			t.Errorf("SimilarItemsTable: item %d raised an error: %v", item, err)
+			continue
+		}
+		if len(similar) != len(candidates) && len(similar) != 1 {
+			t.Errorf("SimilarItemsTable: expected one of %v for item %d, got %v", candidates, item, similar)
+			continue
+		}
+		for _, s := range similar {
+			if !contains(candidates, s) {
+				t.Errorf("SimilarItemsTable: expected one of %v for item %d, got %v", candidates, item, similar)
+			}
+		}
+	}
+
+	if _, err := table.SimilarItems(6, 1); err == nil {
+		t.Errorf("SimilarItemsTable: an unknown item should have raised an error")
+	}
+	if _, err := table.SimilarItems(0, -1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("SimilarItemsTable: expected an invalid configuration error for a negative k, got %v", err)
+	}
+}
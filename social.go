@@ -0,0 +1,179 @@
+package birdland
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/rlouf/birdland/sampler"
+)
+
+// twoStageSampler samples the users related to an item (its fans) from the
+// point of view of a served user. Fans connected to the served user carry
+// their weight in the social graph while every other fan carries the same
+// default weight. The sampler first decides between connected and other fans
+// using their summed weights, then samples either among the connected fans
+// with an alias sampler or uniformly among the others. It draws from the same
+// distribution as an alias sampler built over all the fans, but is built in
+// O(connections) instead of O(fans).
+type twoStageSampler struct {
+	numFans          int
+	connected        []int // sorted positions of the connected fans in ItemsToUsers[item]
+	connectedSampler *sampler.AliasSampler
+	pConnected       float64
+	source           *rand.Rand
+}
+
+// newTwoStageSampler builds the sampler of fans, which must be sorted by
+// ascending user id as is ItemsToUsers, for a user with the given connections.
+func newTwoStageSampler(source *rand.Rand, fans []int, connections map[int]float64,
+	defaultWeight float64) (*twoStageSampler, error) {
+
+	connected := connectedPositions(fans, connections)
+
+	var connectedWeight float64
+	weights := make([]float64, len(connected))
+	for i, pos := range connected {
+		weights[i] = connections[fans[pos]]
+		connectedWeight += weights[i]
+	}
+	defaultTotalWeight := float64(len(fans)-len(connected)) * defaultWeight
+
+	if connectedWeight+defaultTotalWeight <= 0 {
+		return nil, sampler.ErrNullWeights
+	}
+
+	s := twoStageSampler{
+		numFans:    len(fans),
+		connected:  connected,
+		pConnected: connectedWeight / (connectedWeight + defaultTotalWeight),
+		source:     source,
+	}
+
+	if connectedWeight > 0 {
+		connectedSampler, err := sampler.NewAliasSampler(source, weights)
+		if err != nil {
+			return nil, err
+		}
+		s.connectedSampler = connectedSampler
+	}
+
+	return &s, nil
+}
+
+// Sample returns positions in the slice of fans the sampler was built with.
+func (s *twoStageSampler) Sample(numSamples int) []int {
+	samples := make([]int, numSamples)
+	for i := range samples {
+		if s.source.Float64() < s.pConnected {
+			samples[i] = s.connected[s.connectedSampler.Sample(1)[0]]
+			continue
+		}
+
+		// Draw the k-th fan who is not connected, skipping over the
+		// positions of connected fans.
+		k := s.source.Intn(s.numFans - len(s.connected))
+		for _, pos := range s.connected {
+			if pos > k {
+				break
+			}
+			k++
+		}
+		samples[i] = k
+	}
+
+	return samples
+}
+
+// connectedPositions returns the sorted positions in fans of the users that
+// appear in connections. It walks through the smallest of the two
+// collections, using binary search on fans when connections is smaller.
+func connectedPositions(fans []int, connections map[int]float64) []int {
+	var positions []int
+
+	if len(fans) <= len(connections) {
+		for pos, u := range fans {
+			if _, ok := connections[u]; ok {
+				positions = append(positions, pos)
+			}
+		}
+		return positions
+	}
+
+	for u := range connections {
+		pos := sort.SearchInts(fans, u)
+		for ; pos < len(fans) && fans[pos] == u; pos++ {
+			positions = append(positions, pos)
+		}
+	}
+	sort.Ints(positions)
+
+	return positions
+}
+
+// multiHopConnections computes the social weights of the users that can be
+// reached from user in at most hops steps in the social graph. The weight of
+// a path is the product of the weights of its edges, discounted by decay for
+// each hop after the first one, and the weight of a user is the sum of the
+// weights of the paths that lead to them. The served user is not part of
+// their own neighbourhood.
+func multiHopConnections(socialGraph []map[int]float64, user, hops int, decay float64) map[int]float64 {
+	connections := make(map[int]float64, len(socialGraph[user]))
+	frontier := socialGraph[user]
+	discount := 1.0
+	for h := 1; h <= hops && len(frontier) > 0; h++ {
+		for u, w := range frontier {
+			if u != user {
+				connections[u] += discount * w
+			}
+		}
+		if h == hops {
+			break
+		}
+
+		next := make(map[int]float64)
+		for u, w := range frontier {
+			for v, wv := range socialGraph[u] {
+				next[v] += w * wv
+			}
+		}
+		frontier = next
+		discount *= decay
+	}
+
+	return connections
+}
+
+// connectionsSampler samples one of a user's connections in the social graph
+// in proportion to the weight of the connection.
+type connectionsSampler struct {
+	connections []int
+	sampler     *sampler.AliasSampler
+}
+
+// newConnectionsSampler returns the sampler of a user's connections, or nil if
+// the user has no connection with a positive weight. Connections are sorted so
+// that seeded engines perform the same walks.
+func newConnectionsSampler(source *rand.Rand, connections map[int]float64) *connectionsSampler {
+	users := make([]int, 0, len(connections))
+	for u := range connections {
+		users = append(users, u)
+	}
+	sort.Ints(users)
+
+	weights := make([]float64, len(users))
+	for i, u := range users {
+		weights[i] = connections[u]
+	}
+
+	s, err := sampler.NewAliasSampler(source, weights)
+	if err != nil {
+		return nil
+	}
+
+	return &connectionsSampler{users, s}
+}
+
+// Sample returns one of the connections.
+func (s *connectionsSampler) Sample() int {
+	return s.connections[s.sampler.Sample(1)[0]]
+}
@@ -0,0 +1,179 @@
+package birdland
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+type ConnectedPositionsCase struct {
+	Name        string
+	Fans        []int
+	Connections map[int]float64
+	Expected    []int
+}
+
+var connectedPositionsTable = []ConnectedPositionsCase{
+	{
+		Name:        "No connections",
+		Fans:        []int{0, 1, 2},
+		Connections: map[int]float64{},
+		Expected:    []int{},
+	},
+	{
+		Name:        "Fewer connections than fans",
+		Fans:        []int{0, 2, 4, 6, 8},
+		Connections: map[int]float64{8: 1, 2: 1, 3: 1},
+		Expected:    []int{1, 4},
+	},
+	{
+		Name:        "More connections than fans",
+		Fans:        []int{1, 5},
+		Connections: map[int]float64{0: 1, 1: 1, 2: 1, 5: 1},
+		Expected:    []int{0, 1},
+	},
+	{
+		Name:        "Duplicated fans",
+		Fans:        []int{0, 3, 3, 7, 9, 11},
+		Connections: map[int]float64{3: 1},
+		Expected:    []int{1, 2},
+	},
+}
+
+func TestConnectedPositions(t *testing.T) {
+	for _, ex := range connectedPositionsTable {
+		positions := connectedPositions(ex.Fans, ex.Connections)
+		if len(positions) != len(ex.Expected) {
+			t.Errorf("ConnectedPositions: %s: expected %v, got %v", ex.Name, ex.Expected, positions)
+			continue
+		}
+		for i, p := range positions {
+			if p != ex.Expected[i] {
+				t.Errorf("ConnectedPositions: %s: expected %v, got %v", ex.Name, ex.Expected, positions)
+				break
+			}
+		}
+	}
+}
+
+// The two-stage sampler must draw from the same distribution as a sampler
+// built over all the fans of the item.
+func TestTwoStageSamplerDistribution(t *testing.T) {
+	fans := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	connections := map[int]float64{1: 4, 6: 2, 10: 3}
+	defaultWeight := 0.5
+
+	var total float64
+	expected := make([]float64, len(fans))
+	for i, u := range fans {
+		if w, ok := connections[u]; ok {
+			expected[i] = w
+		} else {
+			expected[i] = defaultWeight
+		}
+		total += expected[i]
+	}
+
+	s, err := newTwoStageSampler(rand.New(rand.NewSource(42)), fans, connections, defaultWeight)
+	if err != nil {
+		t.Fatalf("TwoStageSampler: initialization raised an error: %v", err)
+	}
+
+	numSamples := 200000
+	counts := make([]float64, len(fans))
+	for _, pos := range s.Sample(numSamples) {
+		counts[pos]++
+	}
+
+	for i := range fans {
+		p := counts[i] / float64(numSamples)
+		if math.Abs(p-expected[i]/total) > 0.01 {
+			t.Errorf("TwoStageSampler: fan %d drawn with frequency %.3f, expected %.3f", fans[i], p, expected[i]/total)
+		}
+	}
+}
+
+func TestTwoStageSamplerNullWeights(t *testing.T) {
+	_, err := newTwoStageSampler(rand.New(rand.NewSource(42)), []int{0, 1}, map[int]float64{}, 0)
+	if err == nil {
+		t.Errorf("TwoStageSampler: null weights should have raised an error")
+	}
+}
+
+type MultiHopCase struct {
+	Name     string
+	User     int
+	Hops     int
+	Decay    float64
+	Expected map[int]float64
+}
+
+// 0 -> 1 -> 2 -> 3, 0 -> 2 and 2 -> 0
+var multiHopGraph = []map[int]float64{
+	{1: 1, 2: 2},
+	{2: 3},
+	{3: 1, 0: 1},
+	{},
+}
+
+var multiHopTable = []MultiHopCase{
+	{
+		Name:     "Direct connections",
+		User:     0,
+		Hops:     1,
+		Decay:    0.5,
+		Expected: map[int]float64{1: 1, 2: 2},
+	},
+	{
+		Name:     "Two hops",
+		User:     0,
+		Hops:     2,
+		Decay:    0.5,
+		Expected: map[int]float64{1: 1, 2: 2 + 0.5*1*3, 3: 0.5 * 2 * 1},
+	},
+	{
+		Name:     "Three hops",
+		User:     0,
+		Hops:     3,
+		Decay:    0.5,
+		Expected: map[int]float64{1: 1 + 0.25*2*1*1, 2: 2 + 0.5*3 + 0.25*2*1*2, 3: 0.5*2 + 0.25*3*1},
+	},
+	{
+		Name:     "User without connections",
+		User:     3,
+		Hops:     3,
+		Decay:    0.5,
+		Expected: map[int]float64{},
+	},
+}
+
+func TestMultiHopConnections(t *testing.T) {
+	for _, ex := range multiHopTable {
+		connections := multiHopConnections(multiHopGraph, ex.User, ex.Hops, ex.Decay)
+		if len(connections) != len(ex.Expected) {
+			t.Errorf("MultiHopConnections: %s: expected %v, got %v", ex.Name, ex.Expected, connections)
+			continue
+		}
+		for u, w := range ex.Expected {
+			if math.Abs(connections[u]-w) > 1e-9 {
+				t.Errorf("MultiHopConnections: %s: expected %v, got %v", ex.Name, ex.Expected, connections)
+				break
+			}
+		}
+	}
+}
+
+func TestConnectionsSamplerSeed(t *testing.T) {
+	connections := make(map[int]float64)
+	for u := 0; u < 50; u++ {
+		connections[u] = float64(u + 1)
+	}
+
+	first := newConnectionsSampler(rand.New(rand.NewSource(42)), connections)
+	second := newConnectionsSampler(rand.New(rand.NewSource(42)), connections)
+	for i := 0; i < 100; i++ {
+		if a, b := first.Sample(), second.Sample(); a != b {
+			t.Fatalf("ConnectionsSampler: identically seeded samplers drew %d and %d", a, b)
+		}
+	}
+}
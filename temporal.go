@@ -0,0 +1,143 @@
+package birdland
+
+import (
+	"sort"
+	"time"
+)
+
+// occurrence is an interaction of a user with an item at a given time that
+// temporal walks can continue from: the interactions that follow it within
+// the window are events[user][start:end].
+type occurrence struct {
+	user, start, end int
+	time             time.Time
+}
+
+// timeline holds the chronological interactions of each user, on which
+// temporal walks are performed.
+type timeline struct {
+	events      [][]TimedInteraction // interactions of each user sorted by time
+	occurrences [][]occurrence       // interactions with each item that have a continuation, sorted by time
+	window      time.Duration
+}
+
+// newTimeline indexes the timestamped interactions with numItems items. A
+// null window does not limit how far in time walks can go.
+func newTimeline(numItems int, usersToTimedItems [][]TimedInteraction, window time.Duration) *timeline {
+	t := timeline{
+		events:      make([][]TimedInteraction, len(usersToTimedItems)),
+		occurrences: make([][]occurrence, numItems),
+		window:      window,
+	}
+	for user, interactions := range usersToTimedItems {
+		t.events[user] = append([]TimedInteraction(nil), interactions...)
+		t.index(user)
+	}
+	for item := range t.occurrences {
+		t.sortOccurrences(item)
+	}
+
+	return &t
+}
+
+// add inserts new interactions in the user's timeline.
+func (t *timeline) add(user int, interactions []TimedInteraction) {
+	items := make(map[int]bool)
+	for _, e := range t.events[user] {
+		items[e.Item] = true
+	}
+	for _, e := range interactions {
+		items[e.Item] = true
+	}
+
+	for item := range items {
+		occurrences := t.occurrences[item][:0]
+		for _, o := range t.occurrences[item] {
+			if o.user != user {
+				occurrences = append(occurrences, o)
+			}
+		}
+		t.occurrences[item] = occurrences
+	}
+
+	t.events[user] = append(t.events[user], interactions...)
+	t.index(user)
+	for item := range items {
+		t.sortOccurrences(item)
+	}
+}
+
+// index sorts the user's interactions by time and records those that are
+// followed by other interactions within the window. The occurrences of the
+// items must then be sorted with sortOccurrences.
+func (t *timeline) index(user int) {
+	events := t.events[user]
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+
+	var start, end int
+	for pos, e := range events {
+		if start <= pos {
+			start = pos + 1
+		}
+		for start < len(events) && !events[start].Time.After(e.Time) {
+			start++
+		}
+		if end < start {
+			end = start
+		}
+		for end < len(events) && (t.window == 0 || !events[end].Time.After(e.Time.Add(t.window))) {
+			end++
+		}
+		if end > start {
+			t.occurrences[e.Item] = append(t.occurrences[e.Item], occurrence{user, start, end, e.Time})
+		}
+	}
+}
+
+// sortOccurrences sorts the occurrences of item by time.
+func (t *timeline) sortOccurrences(item int) {
+	occurrences := t.occurrences[item]
+	sort.SliceStable(occurrences, func(i, j int) bool { return occurrences[i].time.Before(occurrences[j].time) })
+}
+
+// after returns the occurrences of item that a walk which reached it at time
+// at can continue from: those that did not happen before, within the window.
+func (t *timeline) after(item int, at time.Time) []occurrence {
+	occurrences := t.occurrences[item]
+	start := sort.Search(len(occurrences), func(i int) bool { return !occurrences[i].time.Before(at) })
+	end := len(occurrences)
+	if t.window > 0 {
+		end = sort.Search(len(occurrences), func(i int) bool { return occurrences[i].time.After(at.Add(t.window)) })
+	}
+
+	return occurrences[start:end]
+}
+
+// temporalWalks performs temporal random walks starting from the origins of
+// the walks. From the origin, the walker picks one of the interactions with
+// the item, then moves to one of the items the same user interacted with
+// afterwards, within the window. The following steps only pick interactions
+// that happened from the time the walker reached the item and within the
+// window. Walks thus only move forward in time, and predict what comes next.
+// A walk stops early when no one interacted with anything after the item it
+// reached, so walks may have fewer than Depth steps.
+func (b *Bird) temporalWalks(walks Walks) {
+	for i := range walks {
+		item := walks[i].Origin
+		var at time.Time
+		for d := range walks[i].Steps {
+			occurrences := b.timeline.occurrences[item]
+			if d > 0 {
+				occurrences = b.timeline.after(item, at)
+			}
+			if len(occurrences) == 0 {
+				walks[i].Steps = walks[i].Steps[:d]
+				break
+			}
+			o := occurrences[b.RandSource.Intn(len(occurrences))]
+			e := b.timeline.events[o.user][o.start+b.RandSource.Intn(o.end-o.start)]
+			item, at = e.Item, e.Time
+			walks[i].Steps[d] = Step{Referrer: o.user, Item: item}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package birdland
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// User 0 listens to items 0, 1 and 2 in a row. User 1 listens to item 0,
+// then to item 3 hours later.
+var temporalInteractions = [][]TimedInteraction{
+	{
+		{Item: 2, Weight: 1, Time: decayNow.Add(2 * time.Minute)},
+		{Item: 0, Weight: 1, Time: decayNow},
+		{Item: 1, Weight: 1, Time: decayNow.Add(time.Minute)},
+	},
+	{
+		{Item: 0, Weight: 1, Time: decayNow},
+		{Item: 3, Weight: 1, Time: decayNow.Add(3 * time.Hour)},
+	},
+}
+
+func newTemporalBird(t *testing.T, window time.Duration) *Bird {
+	cfg := NewBirdCfg()
+	cfg.Depth = 2
+	cfg.Draws = 100
+	cfg.Window = window
+	engine, err := New(WithCfg(cfg), WithTimedInteractions(temporalInteractions, decayNow),
+		WithItemWeights([]float64{1, 1, 1, 1, 1}), WithWalkMode(TemporalWalk), WithSeed(42))
+	if err != nil {
+		t.Fatalf("TemporalWalks: initialization raised an error: %v", err)
+	}
+
+	return engine.(*Bird)
+}
+
+func TestTemporalWalks(t *testing.T) {
+	bird := newTemporalBird(t, 90*time.Second)
+
+	walks, _, err := bird.ProcessWalks([]QueryItem{{Item: 0, Weight: 1}})
+	if err != nil {
+		t.Fatalf("TemporalWalks: raised an error: %v", err)
+	}
+	for _, w := range walks {
+		if len(w.Steps) != 2 || w.Steps[0] != (Step{0, 1}) || w.Steps[1] != (Step{0, 2}) {
+			t.Fatalf("TemporalWalks: expected the walk 0 -> 1 -> 2 through user 0, got %v", w.Steps)
+		}
+	}
+
+	// no one listened to anything after item 2
+	walks, _, err = bird.ProcessWalks([]QueryItem{{Item: 2, Weight: 1}})
+	if err != nil {
+		t.Fatalf("TemporalWalks: raised an error: %v", err)
+	}
+	if items, _ := walks.Flatten(); len(items) != 0 {
+		t.Errorf("TemporalWalks: walks from the last item should stop, got %v", items)
+	}
+
+	// without a window, user 1 may continue the walk from item 0 to item 3
+	bird = newTemporalBird(t, 0)
+	items, _, err := bird.Process([]QueryItem{{Item: 0, Weight: 1}})
+	if err != nil {
+		t.Fatalf("TemporalWalks: raised an error: %v", err)
+	}
+	if !contains(items, 3) || !contains(items, 2) || contains(items, 0) {
+		t.Errorf("TemporalWalks: expected walks to reach items 2 and 3 but not item 0, got %v", items)
+	}
+}
+
+func TestTemporalWalksForward(t *testing.T) {
+	// User 0 listens to item 1 after item 0, user 1 listened to item 2 after
+	// item 1 hours before, and user 2 listens to item 3 after item 1 later on.
+	hour := func(h int) time.Time { return decayNow.Add(time.Duration(h) * time.Hour) }
+	interactions := [][]TimedInteraction{
+		{{Item: 0, Weight: 1, Time: hour(10)}, {Item: 1, Weight: 1, Time: hour(11)}},
+		{{Item: 1, Weight: 1, Time: hour(0)}, {Item: 2, Weight: 1, Time: hour(1)}},
+		{{Item: 1, Weight: 1, Time: hour(13)}, {Item: 3, Weight: 1, Time: hour(14)}},
+	}
+	cfg := NewBirdCfg()
+	cfg.Depth = 2
+	cfg.Draws = 100
+	engine, err := New(WithCfg(cfg), WithTimedInteractions(interactions, hour(14)),
+		WithItemWeights([]float64{1, 1, 1, 1}), WithWalkMode(TemporalWalk), WithSeed(42))
+	if err != nil {
+		t.Fatalf("TemporalWalksForward: initialization raised an error: %v", err)
+	}
+	bird := engine.(*Bird)
+
+	walks, _, err := bird.ProcessWalks([]QueryItem{{Item: 0, Weight: 1}})
+	if err != nil {
+		t.Fatalf("TemporalWalksForward: raised an error: %v", err)
+	}
+	for _, w := range walks {
+		if len(w.Steps) != 2 || w.Steps[0] != (Step{0, 1}) || w.Steps[1] != (Step{2, 3}) {
+			t.Fatalf("TemporalWalksForward: expected the walk 0 -> 1 -> 3 through users 0 and 2, got %v", w.Steps)
+		}
+		var previous time.Time
+		for _, s := range w.Steps {
+			var at time.Time
+			for _, i := range interactions[s.Referrer] {
+				if i.Item == s.Item {
+					at = i.Time
+				}
+			}
+			if !at.After(previous) {
+				t.Fatalf("TemporalWalksForward: expected increasing timestamps along the walk %v", w.Steps)
+			}
+			previous = at
+		}
+	}
+
+	// with a one hour window, user 2 listened to item 1 too late
+	cfg.Window = time.Hour
+	engine, err = New(WithCfg(cfg), WithTimedInteractions(interactions, hour(14)),
+		WithItemWeights([]float64{1, 1, 1, 1}), WithWalkMode(TemporalWalk), WithSeed(42))
+	if err != nil {
+		t.Fatalf("TemporalWalksForward: initialization raised an error: %v", err)
+	}
+	walks, _, err = engine.(*Bird).ProcessWalks([]QueryItem{{Item: 0, Weight: 1}})
+	if err != nil {
+		t.Fatalf("TemporalWalksForward: raised an error: %v", err)
+	}
+	for _, w := range walks {
+		if len(w.Steps) != 1 || w.Steps[0] != (Step{0, 1}) {
+			t.Fatalf("TemporalWalksForward: expected the walk 0 -> 1 to stop, got %v", w.Steps)
+		}
+	}
+}
+
+func TestTemporalReweight(t *testing.T) {
+	bird := newTemporalBird(t, 90*time.Second)
+
+	later := decayNow.Add(4 * time.Hour)
+	err := bird.Reweight(later, map[int][]TimedInteraction{1: {{Item: 4, Weight: 1, Time: decayNow.Add(time.Minute)}}})
+	if err != nil {
+		t.Fatalf("TemporalReweight: raised an error: %v", err)
+	}
+
+	items, _, err := bird.Process([]QueryItem{{Item: 0, Weight: 1}})
+	if err != nil {
+		t.Fatalf("TemporalReweight: raised an error: %v", err)
+	}
+	if !contains(items, 4) || contains(items, 3) {
+		t.Errorf("TemporalReweight: expected walks to reach item 4 but not item 3, got %v", items)
+	}
+}
+
+func TestTemporalWalkOptions(t *testing.T) {
+	_, err := New(WithInteractions([][]int{{0}, {1}}), WithWalkMode(TemporalWalk))
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("TemporalWalkOptions: temporal walks without timestamps should raise an invalid configuration error, got %v", err)
+	}
+
+	cfg := NewBirdCfg()
+	cfg.Window = -time.Minute
+	_, err = New(WithCfg(cfg), WithTimedInteractions(temporalInteractions, decayNow), WithWalkMode(TemporalWalk))
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("TemporalWalkOptions: a negative window should raise an invalid configuration error, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package birdland
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// HistoryOrder determines which items of a user's history are kept when the
+// history used as a query is limited to HistoryLimit items.
+type HistoryOrder int
+
+const (
+	MostRecent    HistoryOrder = iota // the last items of UsersToItems[user], for unweighted graphs only
+	HighestWeight                     // the items with the highest interaction weight
+)
+
+// UserQuery builds a query from the user's own history. Each item is
+// weighted by the weight of the interaction in weighted graphs, and by 1
+// otherwise. When HistoryLimit is set, only the most recent or the
+// highest-weighted items are kept depending on HistoryOrder. Unweighted
+// graphs are assumed to list each user's items in chronological order, while
+// weighted graphs do not record the order of interactions and can only keep
+// the highest-weighted items.
+func (b *Bird) UserQuery(user int) ([]QueryItem, error) {
+	if user < 0 || user >= len(b.UsersToItems) {
+		return nil, &UnknownUserError{User: user}
+	}
+
+	userItems := b.UsersToItems[user]
+	query := make([]QueryItem, len(userItems))
+	for i, item := range userItems {
+		query[i] = QueryItem{Item: item, Weight: 1}
+		if b.InteractionWeights != nil {
+			query[i].Weight = b.InteractionWeights[user][i]
+		}
+	}
+
+	limit := b.Cfg.HistoryLimit
+	if limit <= 0 || limit >= len(query) {
+		return query, nil
+	}
+
+	if b.Cfg.HistoryOrder == MostRecent && b.InteractionWeights != nil {
+		return nil, errors.Wrap(ErrInvalidConfig, "weighted graphs cannot keep the most recent items of a history")
+	}
+	if b.Cfg.HistoryOrder == HighestWeight {
+		sort.SliceStable(query, func(i, j int) bool { return query[i].Weight > query[j].Weight })
+		return query[:limit], nil
+	}
+
+	return query[len(query)-limit:], nil
+}
+
+// RecommendForUser recommends k items to the user based on their own
+// history. The items the user already interacted with are excluded.
+func (b *Bird) RecommendForUser(user, k int) ([]int, error) {
+	return recommendForUser(b, b, user, k)
+}
+
+// RecommendForUser recommends k items to the user based on their own
+// history, with walks weighted by the user's social graph. The items the user
+// already interacted with are excluded.
+func (b *Weaver) RecommendForUser(user, k int) ([]int, error) {
+	return recommendForUser(b, b.Bird, user, k)
+}
+
+func recommendForUser(e Engine, b *Bird, user, k int) ([]int, error) {
+	err := validateK(k)
+	if err != nil {
+		return nil, err
+	}
+	query, err := b.UserQuery(user)
+	if err != nil {
+		return nil, err
+	}
+
+	walks, _, err := e.Walk(query, user)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot walk from the history of user %d", user)
+	}
+	items, referrers := walks.Flatten()
+
+	owned := make(map[int]bool, len(b.UsersToItems[user]))
+	for _, item := range b.UsersToItems[user] {
+		owned[item] = true
+	}
+
+	return b.rankItems(RecommendItems(items, referrers), owned, k), nil
+}
@@ -0,0 +1,124 @@
+package birdland
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type UserQueryCase struct {
+	Name     string
+	Limit    int
+	Order    HistoryOrder
+	Weighted bool
+	Expected []QueryItem
+}
+
+var userQueryTable = []UserQueryCase{
+	{
+		Name:     "Full unweighted history",
+		Limit:    0,
+		Order:    MostRecent,
+		Expected: []QueryItem{{3, 1}, {0, 1}, {2, 1}},
+	},
+	{
+		Name:     "Most recent items",
+		Limit:    2,
+		Order:    MostRecent,
+		Expected: []QueryItem{{0, 1}, {2, 1}},
+	},
+	{
+		Name:     "Full weighted history",
+		Limit:    0,
+		Order:    MostRecent,
+		Weighted: true,
+		Expected: []QueryItem{{0, 5}, {2, 1}, {3, 3}},
+	},
+	{
+		Name:     "Highest-weighted items",
+		Limit:    2,
+		Order:    HighestWeight,
+		Weighted: true,
+		Expected: []QueryItem{{0, 5}, {3, 3}},
+	},
+}
+
+func TestUserQuery(t *testing.T) {
+	for _, ex := range userQueryTable {
+		cfg := NewBirdCfg()
+		cfg.HistoryLimit = ex.Limit
+		cfg.HistoryOrder = ex.Order
+
+		var bird *Bird
+		var err error
+		if ex.Weighted {
+			bird, err = NewEmu(cfg, []float64{1, 1, 1, 1}, []map[int]float64{{3: 3, 0: 5, 2: 1}, {1: 1}})
+		} else {
+			bird, err = NewBird(cfg, []float64{1, 1, 1, 1}, [][]int{{3, 0, 2}, {1}})
+		}
+		if err != nil {
+			t.Fatalf("UserQuery: %s: initialization raised an error: %v", ex.Name, err)
+		}
+
+		query, err := bird.UserQuery(0)
+		if err != nil {
+			t.Errorf("UserQuery: %s: raised an error: %v", ex.Name, err)
+			continue
+		}
+		if len(query) != len(ex.Expected) {
+			t.Errorf("UserQuery: %s: expected %v, got %v", ex.Name, ex.Expected, query)
+			continue
+		}
+		for i, q := range query {
+			if q != ex.Expected[i] {
+				t.Errorf("UserQuery: %s: expected %v, got %v", ex.Name, ex.Expected, query)
+				break
+			}
+		}
+	}
+
+	// weighted graphs do not record the order of interactions
+	cfg := NewBirdCfg()
+	cfg.HistoryLimit = 1
+	cfg.HistoryOrder = MostRecent
+	if _, err := NewEmu(cfg, []float64{1, 1, 1, 1}, []map[int]float64{{3: 3, 0: 5, 2: 1}, {1: 1}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("UserQuery: keeping the most recent items of a weighted graph should raise an invalid configuration error, got %v", err)
+	}
+}
+
+func TestRecommendForUser(t *testing.T) {
+	usersToItems := [][]int{{0, 1}, {0, 1, 2}, {1, 3}}
+	socialGraph := []map[int]float64{{1: 1}, {}, {}}
+	cfg := NewWeaverCfg()
+	cfg.Draws = 100
+
+	bird, err := NewBird(cfg.BirdCfg, []float64{1, 1, 1, 1}, usersToItems)
+	if err != nil {
+		t.Fatalf("RecommendForUser: Bird initialization raised an error: %v", err)
+	}
+	weaver, err := NewWeaver(cfg, []float64{1, 1, 1, 1}, usersToItems, socialGraph)
+	if err != nil {
+		t.Fatalf("RecommendForUser: Weaver initialization raised an error: %v", err)
+	}
+
+	for name, recommend := range map[string]func(int, int) ([]int, error){
+		"Bird":   bird.RecommendForUser,
+		"Weaver": weaver.RecommendForUser,
+	} {
+		recommended, err := recommend(0, 10)
+		if err != nil {
+			t.Errorf("RecommendForUser: %s: raised an error: %v", name, err)
+			continue
+		}
+		if len(recommended) != 2 || !contains(recommended, 2) || !contains(recommended, 3) {
+			t.Errorf("RecommendForUser: %s: expected items 2 and 3, got %v", name, recommended)
+		}
+
+		if _, err := recommend(3, 10); err == nil {
+			t.Errorf("RecommendForUser: %s: an unknown user should have raised an error", name)
+		}
+		if _, err := recommend(0, -1); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("RecommendForUser: %s: expected an invalid configuration error for a negative k, got %v", name, err)
+		}
+	}
+}
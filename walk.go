@@ -0,0 +1,60 @@
+package birdland
+
+// Step is one hop of a random walk: from an item to one of the users who
+// interacted with it (the referrer), then to one of the referrer's items.
+type Step struct {
+	Referrer int
+	Item     int
+}
+
+// Walk is the path followed by a random walk that started from the query
+// item Origin. Steps[d] is the hop performed at depth d+1.
+type Walk struct {
+	Origin int
+	Steps  []Step
+}
+
+// Walks is the structured output of the engines' ProcessWalks methods. Unlike
+// the flat slices returned by Process, it keeps track of which query item
+// each visit originates from and at which depth it happened.
+type Walks []Walk
+
+// Flatten returns the visited items and their referrers as two flat slices,
+// ordered by depth first then by walk. This is the format returned by Process
+// and consumed by the recommenders.
+func (w Walks) Flatten() ([]int, []int) {
+	var numSteps, maxDepth int
+	for _, walk := range w {
+		numSteps += len(walk.Steps)
+		if len(walk.Steps) > maxDepth {
+			maxDepth = len(walk.Steps)
+		}
+	}
+
+	items := make([]int, 0, numSteps)
+	referrers := make([]int, 0, numSteps)
+	for d := 0; d < maxDepth; d++ {
+		for _, walk := range w {
+			if d >= len(walk.Steps) {
+				continue
+			}
+			items = append(items, walk.Steps[d].Item)
+			referrers = append(referrers, walk.Steps[d].Referrer)
+		}
+	}
+
+	return items, referrers
+}
+
+// newWalks allocates one walk of the given depth per starting item.
+func newWalks(origins []int, depth int) Walks {
+	walks := make(Walks, len(origins))
+	for i, origin := range origins {
+		walks[i] = Walk{
+			Origin: origin,
+			Steps:  make([]Step, depth),
+		}
+	}
+
+	return walks
+}
@@ -0,0 +1,46 @@
+package birdland
+
+import "testing"
+
+type FlattenCase struct {
+	Name      string
+	Walks     Walks
+	Items     []int
+	Referrers []int
+}
+
+var flattenTable = []FlattenCase{
+	{
+		Name:      "No walks",
+		Walks:     Walks{},
+		Items:     []int{},
+		Referrers: []int{},
+	},
+	{
+		Name: "Walks are flattened depth first",
+		Walks: Walks{
+			{Origin: 0, Steps: []Step{{Referrer: 1, Item: 2}, {Referrer: 3, Item: 4}}},
+			{Origin: 5, Steps: []Step{{Referrer: 6, Item: 7}, {Referrer: 8, Item: 9}}},
+		},
+		Items:     []int{2, 7, 4, 9},
+		Referrers: []int{1, 6, 3, 8},
+	},
+}
+
+func TestWalksFlatten(t *testing.T) {
+	for _, ex := range flattenTable {
+		items, referrers := ex.Walks.Flatten()
+		if len(items) != len(ex.Items) || len(referrers) != len(ex.Referrers) {
+			t.Errorf("Flatten: %s: expected %d items and %d referrers, got %d and %d",
+				ex.Name, len(ex.Items), len(ex.Referrers), len(items), len(referrers))
+			continue
+		}
+		for i := range items {
+			if items[i] != ex.Items[i] || referrers[i] != ex.Referrers[i] {
+				t.Errorf("Flatten: %s: expected %v and %v, got %v and %v",
+					ex.Name, ex.Items, ex.Referrers, items, referrers)
+				break
+			}
+		}
+	}
+}
@@ -1,21 +1,25 @@
 package birdland
 
 import (
-	"fmt"
-
 	"github.com/pkg/errors"
-	"github.com/rlouf/birdland/sampler"
 )
 
 type WeaverCfg struct {
-	DefaultWeight float64 `json:"default_weight"`
+	DefaultWeight    float64 `json:"default_weight"`
+	SamplerCacheSize int     `json:"sampler_cache_size"` // number of (user, item) samplers kept across queries, 0 disables the cache
+	SocialHops       int     `json:"social_hops"`        // length of the social paths considered, values lower than 2 only consider direct connections
+	SocialDecay      float64 `json:"social_decay"`       // discount applied to the weight of a path for each additional hop
+	SocialJump       float64 `json:"social_jump"`        // probability for the walk to jump from a referrer to one of their connections
 	*BirdCfg
 }
 
 func NewWeaverCfg() *WeaverCfg {
 	cfg := WeaverCfg{
-		DefaultWeight: 1,
-		BirdCfg:       NewBirdCfg(),
+		DefaultWeight:    1,
+		SamplerCacheSize: 10000,
+		SocialHops:       1,
+		SocialDecay:      0.5,
+		BirdCfg:          NewBirdCfg(),
 	}
 
 	return &cfg
@@ -26,10 +30,22 @@ func NewWeaverCfg() *WeaverCfg {
 // To avoid storing the full social graph, a mostly empty matrix, we store
 // for each user a map that associates each connection to a weight. Each
 // user that is not connected is attributed a DefaultWeight.
+// When SocialHops is greater than 1, users are also connected to the friends
+// of their friends (and so on) with a weight that decays with the length of
+// the path; these neighbourhoods are cached per served user.
+// When SocialJump is positive, the walks also traverse the social graph: once
+// at a referrer, the walker jumps with probability SocialJump to one of the
+// referrer's connections before sampling an item from their collection.
+// The samplers of related users built for each (served user, item) pair are
+// kept in a LRU cache across queries. SocialGraph should therefore only be
+// modified through UpdateConnections, which invalidates the stale samplers.
 type Weaver struct {
 	Cfg         *WeaverCfg
 	SocialGraph []map[int]float64
 	*Bird
+	samplers       *samplerCache
+	neighbourhoods *connectionsCache
+	jumpSamplers   []*connectionsSampler
 }
 
 // NewWeaver creates a new recommender from input data.
@@ -38,88 +54,151 @@ type Weaver struct {
 func NewWeaver(cfg *WeaverCfg, itemWeights []float64, usersToItems [][]int,
 	socialGraph []map[int]float64) (*Weaver, error) {
 
-	err := validateWeaverInputs(itemWeights, usersToItems, socialGraph)
+	engine, err := New(WithSocialGraph(socialGraph, cfg), WithWalkMode(cfg.walkMode()),
+		WithItemWeights(itemWeights), WithInteractions(usersToItems))
 	if err != nil {
-		return &Weaver{}, errors.Wrap(err, "invalid input")
+		return nil, err
 	}
 
-	bird, err := NewBird(cfg.BirdCfg, itemWeights, usersToItems)
+	return engine.(*Weaver), nil
+}
+
+// NewWeightedWeaver creates a new recommender from input data. Like Emu, it
+// relies on a weighted user-item graph to sample items from the referrers'
+// collections, and like Weaver it samples the referrers according to the
+// social graph.
+func NewWeightedWeaver(cfg *WeaverCfg, itemWeights []float64, usersToWeightedItems []map[int]float64,
+	socialGraph []map[int]float64) (*Weaver, error) {
+
+	engine, err := New(WithSocialGraph(socialGraph, cfg), WithWalkMode(cfg.walkMode()),
+		WithItemWeights(itemWeights), WithWeightedInteractions(usersToWeightedItems))
 	if err != nil {
-		return &Weaver{}, errors.Wrap(err, "couldn't create new bird")
+		return nil, err
+	}
+
+	return engine.(*Weaver), nil
+}
+
+// walkMode returns the walk mode implied by the configuration.
+func (cfg *WeaverCfg) walkMode() WalkMode {
+	if cfg.SocialJump > 0 {
+		return SocialWalk
 	}
 
+	return ItemWalk
+}
+
+// newWeaver wraps a Bird, weighted or not, with a social graph.
+func newWeaver(cfg *WeaverCfg, bird *Bird, socialGraph []map[int]float64) *Weaver {
 	b := Weaver{
 		cfg,
 		socialGraph,
 		bird,
+		newSamplerCache(cfg.SamplerCacheSize),
+		newConnectionsCache(cfg.SamplerCacheSize),
+		nil,
+	}
+
+	if cfg.SocialJump > 0 {
+		b.jumpSamplers = make([]*connectionsSampler, len(socialGraph))
+		for u, connections := range socialGraph {
+			b.jumpSamplers[u] = newConnectionsSampler(bird.RandSource, connections)
+		}
 	}
 
-	return &b, nil
+	return &b
 }
 
 // Process returns a slice of items that were visited during the random walks
 // along with the users that referred these items.
 func (b *Weaver) Process(query []QueryItem, user int) ([]int, []int, error) {
+	walks, _, err := b.ProcessWalks(query, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, referrers := walks.Flatten()
+
+	return items, referrers, nil
+}
+
+// ProcessWalks returns the full path of the random walks performed on behalf
+// of user, starting from items sampled from the query, along with the query
+// items that were dropped because no one has interacted with them.
+func (b *Weaver) ProcessWalks(query []QueryItem, user int) (Walks, []QueryItem, error) {
+	return b.processWalks(query, func(items []int) ([]int, []int, error) {
+		return b.step(items, user)
+	})
+}
+
+// processWalks samples the starting items from the query and performs the
+// walks, step being called once per depth.
+func (b *Weaver) processWalks(query []QueryItem, step func(items []int) ([]int, []int, error)) (Walks, []QueryItem, error) {
 	if len(query) == 0 {
-		return nil, nil, errors.New("the input query is empty")
+		return nil, nil, ErrEmptyQuery
 	}
 
-	stepItems, err := b.sampleItemsFromQuery(query)
+	stepItems, dropped, err := b.sampleItemsFromQuery(query)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "cannot sample items from the query")
 	}
 
-	var items []int
-	var referrers []int
+	walks := newWalks(stepItems, b.Cfg.Depth)
 	for d := 0; d < b.Cfg.Depth; d++ {
-		stepItems, stepReferrers, err := b.step(stepItems, user)
+		var stepReferrers []int
+		stepItems, stepReferrers, err = step(stepItems)
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "cannot step through items")
 		}
-		items = append(items, stepItems...)
-		referrers = append(referrers, stepReferrers...)
+		for i := range walks {
+			walks[i].Steps[d] = Step{Referrer: stepReferrers[i], Item: stepItems[i]}
+		}
 	}
 
-	return items, referrers, nil
+	return walks, dropped, nil
 }
 
 // step performs one random walk step for each incoming item.
 // it returns a slice of visited items along with the 'referrers', i.e. the
-// users that were visited to reach these items.
+// users that were visited to reach these items. When the walker jumps to a
+// connection, the connection is the referrer.
 func (b *Weaver) step(items []int, user int) ([]int, []int, error) {
 
-	if user >= len(b.SocialGraph) {
-		return nil, nil, fmt.Errorf("user %d does not belong to the social graph", user)
+	if user < 0 || user >= len(b.SocialGraph) {
+		return nil, nil, &UnknownUserError{User: user}
 	}
 
+	return b.socialStep(items, func(item int) (indexSampler, error) {
+		s, err := b.relatedUsersSampler(user, item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not initialize users' sampler for user %d and item %d", user, item)
+		}
+		return s, nil
+	})
+}
+
+// socialStep performs one random walk step for each incoming item, drawing
+// the referrers with the samplers returned by relatedUsersSampler.
+func (b *Weaver) socialStep(items []int, relatedUsersSampler func(item int) (indexSampler, error)) ([]int, []int, error) {
+
 	referrers := make([]int, len(items))
-	itemUserSamplers := make(map[int]*sampler.AliasSampler)
+	itemUserSamplers := make(map[int]indexSampler)
 
 	for i, item := range items {
 		relatedUsers := b.ItemsToUsers[item]
 
 		if len(relatedUsers) == 0 {
-			return nil, nil, errors.New("the item refers to an item no one has interacted with")
+			return nil, nil, &DeadEndError{Item: item}
 		}
 
-		// for each item, create a sampler of related users weighted by socialCoef
-		// (with default weight value 1)
 		if _, ok := itemUserSamplers[item]; !ok {
-			weightedRelatedUsers := make([]float64, len(relatedUsers))
-			for j, u := range relatedUsers {
-				if w, ok := b.SocialGraph[user][u]; ok {
-					weightedRelatedUsers[j] = w
-				} else {
-					weightedRelatedUsers[j] = b.Cfg.DefaultWeight
-				}
-			}
-			itemUserSampler, err := sampler.NewAliasSampler(b.RandSource, weightedRelatedUsers)
-			itemUserSamplers[item] = itemUserSampler
+			itemUserSampler, err := relatedUsersSampler(item)
 			if err != nil {
-				return nil, nil, errors.Wrapf(err, "could not initialize users' sampler for user %d and item %d", user, item)
+				return nil, nil, err
 			}
+			itemUserSamplers[item] = itemUserSampler
 		}
-		referrers[i] = relatedUsers[itemUserSamplers[item].Sample(1)[0]]
+		referrers[i] = b.jump(relatedUsers[itemUserSamplers[item].Sample(1)[0]])
 	}
 
 	newItems := make([]int, len(items))
@@ -130,50 +209,129 @@ func (b *Weaver) step(items []int, user int) ([]int, []int, error) {
 	return newItems, referrers, nil
 }
 
-// validateWeaverInput checks the validity of the data fed to Weaver.  It returns
-// an error when it identifies a discrepancy that could make the processing
-// algorithm crash.
-func validateWeaverInputs(itemWeights []float64, usersToItems [][]int, socialGraph []map[int]float64) error {
+// jump moves the walker from the referrer to one of their connections with
+// probability SocialJump. It returns the user the walk continues from.
+func (b *Weaver) jump(referrer int) int {
+	if b.jumpSamplers == nil || b.jumpSamplers[referrer] == nil {
+		return referrer
+	}
+	if b.RandSource.Float64() >= b.Cfg.SocialJump {
+		return referrer
+	}
+
+	return b.jumpSamplers[referrer].Sample()
+}
+
+// relatedUsersSampler returns a sampler of the users related to item weighted
+// by socialCoef (with default weight value 1) from the point of view of user.
+// Samplers are looked up in the cache before being built.
+func (b *Weaver) relatedUsersSampler(user, item int) (indexSampler, error) {
+	if s, ok := b.samplers.Get(user, item); ok {
+		return s, nil
+	}
 
-	if len(itemWeights) == 0 {
-		return errors.New("empty slice of item weights")
+	s, err := newTwoStageSampler(b.RandSource, b.ItemsToUsers[item], b.connections(user), b.Cfg.DefaultWeight)
+	if err != nil {
+		return nil, err
 	}
-	if len(usersToItems) == 0 {
-		return errors.New("empty users to items adjacency table")
+	b.samplers.Add(user, item, s)
+
+	return s, nil
+}
+
+// connections returns the weights of the users connected to user, following
+// paths of at most SocialHops steps in the social graph.
+func (b *Weaver) connections(user int) map[int]float64 {
+	if b.Cfg.SocialHops < 2 {
+		return b.SocialGraph[user]
+	}
+	if c, ok := b.neighbourhoods.Get(user); ok {
+		return c
 	}
 
-	// Check that there is a weight for each item present in adjacency tables.
-	numItems := len(itemWeights)
-	var m int
-	for _, userItems := range usersToItems {
-		for _, item := range userItems {
-			if item > m {
-				m = item
-			}
+	c := multiHopConnections(b.SocialGraph, user, b.Cfg.SocialHops, b.Cfg.SocialDecay)
+	b.neighbourhoods.Add(user, c)
+
+	return c
+}
+
+// UpdateConnections replaces the connections of user in the social graph and
+// invalidates the samplers that were built from the previous ones.
+func (b *Weaver) UpdateConnections(user int, connections map[int]float64) error {
+	if user < 0 || user >= len(b.SocialGraph) {
+		return &UnknownUserError{User: user}
+	}
+	for u, w := range connections {
+		if u < 0 || u >= len(b.SocialGraph) {
+			return &UnknownUserError{User: u}
+		}
+		if w < 0 {
+			return errors.Wrap(ErrInvalidInput, "weights in the social graph must be positive")
 		}
 	}
-	if numItems <= m {
-		return fmt.Errorf("there are more items (%d) in UsersToItems than there are weights (%d)", m, numItems)
+
+	b.SocialGraph[user] = connections
+	if b.jumpSamplers != nil {
+		b.jumpSamplers[user] = newConnectionsSampler(b.RandSource, connections)
+	}
+	if b.Cfg.SocialHops < 2 {
+		b.samplers.InvalidateUser(user)
+		return nil
 	}
 
-	if len(socialGraph) != len(usersToItems) {
-		return errors.New("UsersToItems and the social graph don't contain the same number of users")
+	// the neighbourhoods of other users may go through user
+	b.neighbourhoods.Purge()
+	b.samplers.Purge()
+
+	return nil
+}
+
+// PurgeSamplers empties the caches of related users' samplers and of social
+// neighbourhoods. It must be called after SocialGraph, ItemsToUsers or the
+// social parameters of the configuration are modified directly.
+func (b *Weaver) PurgeSamplers() {
+	b.samplers.Purge()
+	b.neighbourhoods.Purge()
+}
+
+// validateWeaverCfg checks the social parameters of the configuration.
+func validateWeaverCfg(cfg *WeaverCfg) error {
+	if cfg.DefaultWeight < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the default weight must be positive")
+	}
+	if cfg.SocialDecay < 0 {
+		return errors.Wrap(ErrInvalidConfig, "the social decay must be positive")
 	}
+	if cfg.SocialJump < 0 || cfg.SocialJump > 1 {
+		return errors.Wrap(ErrInvalidConfig, "the social jump probability must be between 0 and 1")
+	}
+
+	return nil
+}
 
-	numUsers := len(socialGraph)
-	m = 0
+// validateSocialGraph checks that the social graph connects the numUsers
+// users of the user-item graph with positive weights.
+func validateSocialGraph(numUsers int, socialGraph []map[int]float64) error {
+	if len(socialGraph) != numUsers {
+		return errors.Wrap(ErrInvalidInput, "UsersToItems and the social graph don't contain the same number of users")
+	}
+
+	var m int
 	for _, friendsCoef := range socialGraph {
 		for user, w := range friendsCoef {
+			if user < 0 {
+				return errors.Wrap(ErrInvalidInput, "user ids in the social graph must be positive")
+			}
 			if user > m {
 				m = user
 			}
 			if w < 0 {
-				return errors.New("weights in the social graph must be positive")
+				return errors.Wrap(ErrInvalidInput, "weights in the social graph must be positive")
 			}
 		}
 	}
 	if numUsers <= m {
-		return errors.New("some users mentioned in the connections are otherwise absent from the graph")
+		return errors.Wrap(ErrInvalidInput, "some users mentioned in the connections are otherwise absent from the graph")
 	}
 
 	return nil
@@ -6,13 +6,14 @@ import (
 )
 
 type WeaverInitCase struct {
-	Name         string
-	ItemWeights  []float64
-	UsersToItems [][]int
-	SocialGraph  []map[int]float64
-	Draws        int
-	Depth        int
-	Valid        bool
+	Name          string
+	ItemWeights   []float64
+	UsersToItems  [][]int
+	SocialGraph   []map[int]float64
+	Draws         int
+	Depth         int
+	DefaultWeight float64 // NewWeaverCfg's default when zero
+	Valid         bool
 }
 
 var weaverInitTable = []WeaverInitCase{
@@ -88,6 +89,15 @@ var weaverInitTable = []WeaverInitCase{
 		Draws:        1,
 		Valid:        false,
 	},
+	{
+		Name:         "Negative user in the social graph",
+		ItemWeights:  []float64{0.1, 0.2},
+		UsersToItems: [][]int{[]int{0}, []int{1}},
+		SocialGraph:  []map[int]float64{{-1: 1.}, {0: 1.}},
+		Depth:        1,
+		Draws:        1,
+		Valid:        false,
+	},
 	{
 		Name:         "Discrepancy in the number of users",
 		ItemWeights:  []float64{0.1, 0.2, 0.4},
@@ -106,6 +116,16 @@ var weaverInitTable = []WeaverInitCase{
 		Draws:        1,
 		Valid:        false,
 	},
+	{
+		Name:          "Negative default weight",
+		ItemWeights:   []float64{1, 1},
+		UsersToItems:  [][]int{[]int{0}, []int{1}},
+		SocialGraph:   []map[int]float64{{1: 1.}, {0: 1.}},
+		Depth:         1,
+		Draws:         1,
+		DefaultWeight: -1,
+		Valid:         false,
+	},
 	{
 		Name:         "Perfectly valid input",
 		ItemWeights:  []float64{1, 1},
@@ -122,6 +142,9 @@ func TestWeaverInitialization(t *testing.T) {
 		cfg := NewWeaverCfg()
 		cfg.Depth = ex.Depth
 		cfg.Draws = ex.Draws
+		if ex.DefaultWeight != 0 {
+			cfg.DefaultWeight = ex.DefaultWeight
+		}
 
 		_, err := NewWeaver(cfg, ex.ItemWeights, ex.UsersToItems, ex.SocialGraph)
 		if err != nil && ex.Valid {
@@ -135,6 +158,186 @@ func TestWeaverInitialization(t *testing.T) {
 	}
 }
 
+func TestWeaverUpdateConnections(t *testing.T) {
+	usersToItems := [][]int{{0}, {0, 1}, {0, 2}}
+	socialGraph := []map[int]float64{{1: 1e9}, {}, {}}
+	cfg := NewWeaverCfg()
+	cfg.DefaultWeight = 1e-9
+	cfg.Draws = 50
+
+	weaver, err := NewWeaver(cfg, []float64{1, 1, 1}, usersToItems, socialGraph)
+	if err != nil {
+		t.Fatalf("UpdateConnections: Weaver initialization raised an error: %v", err)
+	}
+
+	query := []QueryItem{{Item: 0, Weight: 1}}
+	_, referrers, err := weaver.Process(query, 0)
+	if err != nil {
+		t.Fatalf("UpdateConnections: processing raised an error: %v", err)
+	}
+	for _, r := range referrers {
+		if r != 1 {
+			t.Fatalf("UpdateConnections: expected user 1 to refer every item, got %d", r)
+		}
+	}
+
+	err = weaver.UpdateConnections(0, map[int]float64{2: 1e9})
+	if err != nil {
+		t.Fatalf("UpdateConnections: updating the connections raised an error: %v", err)
+	}
+	_, referrers, err = weaver.Process(query, 0)
+	if err != nil {
+		t.Fatalf("UpdateConnections: processing raised an error: %v", err)
+	}
+	for _, r := range referrers {
+		if r != 2 {
+			t.Fatalf("UpdateConnections: expected the cached sampler to be invalidated and "+
+				"user 2 to refer every item, got %d", r)
+		}
+	}
+
+	if err := weaver.UpdateConnections(0, map[int]float64{3: 1}); err == nil {
+		t.Errorf("UpdateConnections: connecting to an unknown user should have raised an error")
+	}
+}
+
+func TestWeaverMultiHop(t *testing.T) {
+	// user 0 is only connected to user 1, who is connected to user 2. Item 0
+	// is shared by users 2 and 3.
+	usersToItems := [][]int{{1}, {1}, {0}, {0}}
+	socialGraph := []map[int]float64{{1: 1}, {2: 1e9}, {}, {}}
+	cfg := NewWeaverCfg()
+	cfg.DefaultWeight = 1e-9
+	cfg.Draws = 50
+	cfg.SocialHops = 2
+
+	weaver, err := NewWeaver(cfg, []float64{1, 1}, usersToItems, socialGraph)
+	if err != nil {
+		t.Fatalf("MultiHop: Weaver initialization raised an error: %v", err)
+	}
+
+	_, referrers, err := weaver.Process([]QueryItem{{Item: 0, Weight: 1}}, 0)
+	if err != nil {
+		t.Fatalf("MultiHop: processing raised an error: %v", err)
+	}
+	for _, r := range referrers {
+		if r != 2 {
+			t.Fatalf("MultiHop: expected the friend of a friend to refer every item, got %d", r)
+		}
+	}
+}
+
+func TestWeaverSocialJump(t *testing.T) {
+	// user 1 is the only fan of item 0 and is connected to user 2, the only
+	// fan of item 1.
+	usersToItems := [][]int{{2}, {0}, {1}}
+	socialGraph := []map[int]float64{{}, {2: 1}, {}}
+
+	for _, jump := range []float64{0, 1} {
+		cfg := NewWeaverCfg()
+		cfg.Draws = 50
+		cfg.SocialJump = jump
+
+		weaver, err := NewWeaver(cfg, []float64{1, 1, 1}, usersToItems, socialGraph)
+		if err != nil {
+			t.Fatalf("SocialJump: Weaver initialization raised an error: %v", err)
+		}
+
+		items, referrers, err := weaver.Process([]QueryItem{{Item: 0, Weight: 1}}, 0)
+		if err != nil {
+			t.Fatalf("SocialJump: processing raised an error: %v", err)
+		}
+
+		expectedItem, expectedReferrer := 0, 1
+		if jump == 1 {
+			expectedItem, expectedReferrer = 1, 2
+		}
+		for i := range items {
+			if items[i] != expectedItem || referrers[i] != expectedReferrer {
+				t.Fatalf("SocialJump: with probability %v, expected item %d referred by %d, got item %d referred by %d",
+					jump, expectedItem, expectedReferrer, items[i], referrers[i])
+			}
+		}
+	}
+
+	cfg := NewWeaverCfg()
+	cfg.SocialJump = 2
+	if _, err := NewWeaver(cfg, []float64{1, 1, 1}, usersToItems, socialGraph); err == nil {
+		t.Errorf("SocialJump: a jump probability greater than 1 should have raised an error")
+	}
+}
+
+type WeightedWeaverInitCase struct {
+	Name                 string
+	ItemWeights          []float64
+	UsersToWeightedItems []map[int]float64
+	SocialGraph          []map[int]float64
+	Valid                bool
+}
+
+var weightedWeaverInitTable = []WeightedWeaverInitCase{
+	{
+		Name:                 "Negative interaction weight",
+		ItemWeights:          []float64{1, 1},
+		UsersToWeightedItems: []map[int]float64{{0: 1.}, {1: -1.}},
+		SocialGraph:          []map[int]float64{{1: 1.}, {0: 1.}},
+		Valid:                false,
+	},
+	{
+		Name:                 "Different number of users in the social graph",
+		ItemWeights:          []float64{1, 1},
+		UsersToWeightedItems: []map[int]float64{{0: 1.}, {1: 1.}},
+		SocialGraph:          []map[int]float64{{}},
+		Valid:                false,
+	},
+	{
+		Name:                 "Perfectly valid input",
+		ItemWeights:          []float64{1, 1},
+		UsersToWeightedItems: []map[int]float64{{0: 1.}, {1: 1.}},
+		SocialGraph:          []map[int]float64{{1: 1.}, {0: 1.}},
+		Valid:                true,
+	},
+}
+
+func TestWeightedWeaverInitialization(t *testing.T) {
+	for _, ex := range weightedWeaverInitTable {
+		_, err := NewWeightedWeaver(NewWeaverCfg(), ex.ItemWeights, ex.UsersToWeightedItems, ex.SocialGraph)
+		if err != nil && ex.Valid {
+			t.Errorf("WeightedWeaverInitialization: %s: initialization should not have raised "+
+				"an error but did: %v", ex.Name, err)
+		}
+		if err == nil && !ex.Valid {
+			t.Errorf("WeightedWeaverInitialization: %s: initialization should have raised "+
+				"an error but did not", ex.Name)
+		}
+	}
+}
+
+func TestWeightedWeaverProcess(t *testing.T) {
+	// user 1, the served user's only weighted connection, mostly plays item 1.
+	usersToWeightedItems := []map[int]float64{{0: 1}, {0: 1e-9, 1: 1e9}, {0: 1, 2: 1}}
+	socialGraph := []map[int]float64{{1: 1e9}, {}, {}}
+	cfg := NewWeaverCfg()
+	cfg.DefaultWeight = 1e-9
+	cfg.Draws = 50
+
+	weaver, err := NewWeightedWeaver(cfg, []float64{1, 1, 1}, usersToWeightedItems, socialGraph)
+	if err != nil {
+		t.Fatalf("WeightedWeaverProcess: initialization raised an error: %v", err)
+	}
+
+	items, referrers, err := weaver.Process([]QueryItem{{Item: 0, Weight: 1}}, 0)
+	if err != nil {
+		t.Fatalf("WeightedWeaverProcess: processing raised an error: %v", err)
+	}
+	for i := range items {
+		if items[i] != 1 || referrers[i] != 1 {
+			t.Fatalf("WeightedWeaverProcess: expected item 1 referred by user 1, got item %d referred by %d",
+				items[i], referrers[i])
+		}
+	}
+}
+
 func benchmarkWeaverStep(querySize, numUsers, numItems int, b *testing.B) {
 	usersToItems := make([][]int, numUsers)
 	for i := 0; i < numUsers; i++ {